@@ -95,6 +95,12 @@ func Str(key string, value string) Attr {
 	return core.Str(key, value)
 }
 
+// Secret returns an [Attr] for a value that must never be logged, like a password or a token.
+// Only the key gets into the record.
+func Secret(key string, value string) Attr {
+	return core.Secret(key, value)
+}
+
 // Stg returns an [Attr] for fmt.Stringer value.
 func Stg(key string, value fmt.Stringer) Attr {
 	return core.Stg(key, value)
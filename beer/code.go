@@ -0,0 +1,41 @@
+package beer
+
+import (
+	"github.com/sirkon/blog/internal/core"
+)
+
+// Code is a stable documented error code. Put it on errors with [Spec]:
+//
+//	var CodeDBTimeout = beer.DefineCode("DB_TIMEOUT", beer.CodeDescription("database query timed out"))
+//
+//	…
+//	return beer.Spec(beer.Wrap(err, "query accounts"), CodeDBTimeout)
+//
+// The code is written into the error context with the @code key then, so it is
+// shown in logs as a structured field. Use [IsCode] to check for a certain code,
+// or AsSpec[*beer.Code] to get the most recent one.
+type Code = core.ErrorCode
+
+// CodeOption sets up an optional property of [Code].
+type CodeOption = core.ErrorCodeOption
+
+// DefineCode defines a code with the given name. It panics if the name is empty or
+// is already defined.
+func DefineCode(name string, opts ...CodeOption) *Code {
+	return core.DefineErrorCode(name, opts...)
+}
+
+// CodeDescription sets a human readable description of the code.
+func CodeDescription(text string) CodeOption {
+	return core.ErrorCodeDescription(text)
+}
+
+// Codes returns all defined codes sorted by their names. Meant for generating error catalogs.
+func Codes() []*Code {
+	return core.ErrorCodes()
+}
+
+// IsCode checks if the code was put on the err.
+func IsCode(err error, code *Code) bool {
+	return core.IsErrorCode(err, code)
+}
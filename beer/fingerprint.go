@@ -0,0 +1,17 @@
+package beer
+
+import (
+	"github.com/sirkon/blog/internal/core"
+)
+
+// Fingerprint returns a stable hash of the error to group or deduplicate identical errors.
+//
+// It is computed from the chain of stages, their messages and locations, while context values
+// are ignored. So, errors created by the same code path have the same fingerprint no matter
+// what values were put into their contexts. Values formatted into messages, like IDs of [Wrapf],
+// are ignored as well: words with digits and quoted values are replaced with placeholders.
+//
+// Texts are hashed for errors not made with this package.
+func Fingerprint(err error) uint64 {
+	return core.ErrorFingerprint(err)
+}
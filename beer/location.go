@@ -12,7 +12,24 @@ func InsertLocationsOn() {
 	core.InsertLocationsOn()
 }
 
-// InsertLocationsOff disables locations capturing.
+// InsertLocationsOff disables locations capturing. Locations enabled
+// with [InsertLocationsOnFor] are still captured.
 func InsertLocationsOff() {
 	core.InsertLocationsOff()
 }
+
+// InsertLocationsOnFor enables locations capturing only for errors created
+// in packages with given import paths and their subpackages:
+//
+//	beer.InsertLocationsOnFor("github.com/company/service/internal/storage")
+//
+// It is safe to be called at runtime, to turn on locations for one noisy subsystem
+// in production for instance.
+func InsertLocationsOnFor(paths ...string) {
+	core.InsertLocationsOnFor(paths...)
+}
+
+// InsertLocationsOffFor cancels [InsertLocationsOnFor] for given import paths.
+func InsertLocationsOffFor(paths ...string) {
+	core.InsertLocationsOffFor(paths...)
+}
@@ -0,0 +1,2 @@
+// Package status maps [beer.Error] specs and sentinel errors to HTTP and gRPC status codes.
+package status
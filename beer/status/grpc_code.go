@@ -0,0 +1,73 @@
+package status
+
+import (
+	"strconv"
+)
+
+// GRPCCode numeric gRPC status code. It matches codes.Code of google.golang.org/grpc/codes,
+// so a local adapter is just a conversion:
+//
+//	grpcstatus.New(codes.Code(st.GRPC), st.Message)
+type GRPCCode uint32
+
+// gRPC status codes.
+const (
+	GRPCOK                 GRPCCode = 0
+	GRPCCanceled           GRPCCode = 1
+	GRPCUnknown            GRPCCode = 2
+	GRPCInvalidArgument    GRPCCode = 3
+	GRPCDeadlineExceeded   GRPCCode = 4
+	GRPCNotFound           GRPCCode = 5
+	GRPCAlreadyExists      GRPCCode = 6
+	GRPCPermissionDenied   GRPCCode = 7
+	GRPCResourceExhausted  GRPCCode = 8
+	GRPCFailedPrecondition GRPCCode = 9
+	GRPCAborted            GRPCCode = 10
+	GRPCOutOfRange         GRPCCode = 11
+	GRPCUnimplemented      GRPCCode = 12
+	GRPCInternal           GRPCCode = 13
+	GRPCUnavailable        GRPCCode = 14
+	GRPCDataLoss           GRPCCode = 15
+	GRPCUnauthenticated    GRPCCode = 16
+)
+
+func (c GRPCCode) String() string {
+	switch c {
+	case GRPCOK:
+		return "OK"
+	case GRPCCanceled:
+		return "Canceled"
+	case GRPCUnknown:
+		return "Unknown"
+	case GRPCInvalidArgument:
+		return "InvalidArgument"
+	case GRPCDeadlineExceeded:
+		return "DeadlineExceeded"
+	case GRPCNotFound:
+		return "NotFound"
+	case GRPCAlreadyExists:
+		return "AlreadyExists"
+	case GRPCPermissionDenied:
+		return "PermissionDenied"
+	case GRPCResourceExhausted:
+		return "ResourceExhausted"
+	case GRPCFailedPrecondition:
+		return "FailedPrecondition"
+	case GRPCAborted:
+		return "Aborted"
+	case GRPCOutOfRange:
+		return "OutOfRange"
+	case GRPCUnimplemented:
+		return "Unimplemented"
+	case GRPCInternal:
+		return "Internal"
+	case GRPCUnavailable:
+		return "Unavailable"
+	case GRPCDataLoss:
+		return "DataLoss"
+	case GRPCUnauthenticated:
+		return "Unauthenticated"
+	default:
+		return "Code(" + strconv.FormatUint(uint64(c), 10) + ")"
+	}
+}
@@ -0,0 +1,345 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirkon/blog/beer"
+	"github.com/sirkon/blog/internal/core"
+)
+
+// Mapping describes how errors are represented to clients.
+type Mapping struct {
+	HTTP int
+	GRPC GRPCCode
+
+	// Message is a client-safe message. Error texts are never shown to clients since they
+	// may leak internal details. http.StatusText(HTTP) is used when empty.
+	Message string
+
+	// Fields is a whitelist of error context keys to be shown to clients. Only keys set on stages
+	// of the error are matched, keys within groups and nested errors are never shown.
+	Fields []string
+}
+
+// Status is a client-safe representation of an error.
+type Status struct {
+	HTTP    int
+	GRPC    GRPCCode
+	Message string
+	Fields  []Field
+}
+
+// Field is a whitelisted error context value.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Registry maps spec types and sentinel errors to statuses. It is safe for concurrent use.
+type Registry struct {
+	lock      sync.RWMutex
+	codes     []codeEntry
+	specs     []specEntry
+	sentinels []sentinelEntry
+	fallback  Mapping
+}
+
+type codeEntry struct {
+	code    *beer.Code
+	mapping Mapping
+}
+
+type specEntry struct {
+	// match checks if the spec is of the registered type.
+	match   func(spec any) bool
+	spec    any
+	mapping Mapping
+}
+
+type sentinelEntry struct {
+	err     error
+	mapping Mapping
+}
+
+// NewRegistry creates a registry with the fallback mapping to 500 Internal Server Error
+// and [GRPCInternal].
+func NewRegistry() *Registry {
+	return &Registry{
+		fallback: Mapping{
+			HTTP:    http.StatusInternalServerError,
+			GRPC:    GRPCInternal,
+			Message: "internal error",
+		},
+	}
+}
+
+// RegisterSpec maps errors having a spec of type T, see [beer.Spec]. T may be an interface,
+// specs implementing it are matched then.
+// The zero value of T is used as a spec of errors restored with [Registry.FromStatus],
+// errors are restored without a spec for interfaces since their zero values are nil.
+func RegisterSpec[T any](r *Registry, m Mapping) {
+	var spec T
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.specs = append(r.specs, specEntry{
+		match: func(spec any) bool {
+			_, ok := spec.(T)
+			return ok
+		},
+		spec:    spec,
+		mapping: m,
+	})
+}
+
+// RegisterCode maps errors having the code, see [beer.DefineCode]. Codes take
+// precedence over other specs of the same error.
+func (r *Registry) RegisterCode(code *beer.Code, m Mapping) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.codes = append(r.codes, codeEntry{
+		code:    code,
+		mapping: m,
+	})
+}
+
+// RegisterSentinel maps errors matching err with [errors.Is].
+func (r *Registry) RegisterSentinel(err error, m Mapping) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.sentinels = append(r.sentinels, sentinelEntry{
+		err:     err,
+		mapping: m,
+	})
+}
+
+// SetFallback sets a mapping for errors that match nothing.
+func (r *Registry) SetFallback(m Mapping) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.fallback = m
+}
+
+// Status computes a status of the error. The most recent registered code of the error wins,
+// then the most recent registered spec. Sentinels are checked after them in the order they
+// were registered.
+func (r *Registry) Status(err error) Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return newStatus(err, r.lookup(err))
+}
+
+func (r *Registry) lookup(err error) Mapping {
+	for spec := range core.Specs(err) {
+		code, ok := spec.(*beer.Code)
+		if !ok {
+			continue
+		}
+		for _, entry := range r.codes {
+			if entry.code == code {
+				return entry.mapping
+			}
+		}
+	}
+
+	for spec := range core.Specs(err) {
+		for _, entry := range r.specs {
+			if entry.match(spec) {
+				return entry.mapping
+			}
+		}
+	}
+
+	for _, entry := range r.sentinels {
+		if errors.Is(err, entry.err) {
+			return entry.mapping
+		}
+	}
+
+	return r.fallback
+}
+
+func newStatus(err error, m Mapping) Status {
+	res := Status{
+		HTTP:    m.HTTP,
+		GRPC:    m.GRPC,
+		Message: m.Message,
+	}
+	if res.Message == "" {
+		res.Message = http.StatusText(m.HTTP)
+	}
+
+	for key, value := range core.ErrorContext(err, m.Fields...) {
+		var found bool
+		for i, field := range res.Fields {
+			if field.Key == key {
+				// The most recent value wins.
+				res.Fields[i].Value = value
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.Fields = append(res.Fields, Field{Key: key, Value: value})
+		}
+	}
+
+	return res
+}
+
+// WriteHTTP writes a status of the error as a JSON response like
+//
+//	{"message": "account not found", "fields": {"account-id": 42}}
+func (r *Registry) WriteHTTP(w http.ResponseWriter, err error) {
+	st := r.Status(err)
+
+	data, merr := json.Marshal(st.httpBody())
+	if merr != nil {
+		// Whitelisted values are of basic types, this is not going to happen.
+		data = []byte(`{"message":` + fmt.Sprintf("%q", st.Message) + `}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(st.HTTP)
+	_, _ = w.Write(data)
+}
+
+type statusHTTPBody struct {
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+func (s Status) httpBody() statusHTTPBody {
+	res := statusHTTPBody{
+		Message: s.Message,
+	}
+	if len(s.Fields) > 0 {
+		res.Fields = make(map[string]any, len(s.Fields))
+		for _, field := range s.Fields {
+			res.Fields[field.Key] = field.Value
+		}
+	}
+
+	return res
+}
+
+// FromHTTP restores an error on the client side with the HTTP status code and a message.
+func (r *Registry) FromHTTP(code int, message string) error {
+	return r.FromStatus(Status{
+		HTTP:    code,
+		GRPC:    GRPCUnknown,
+		Message: message,
+	})
+}
+
+// FromGRPC restores an error on the client side with the gRPC status code and a message.
+func (r *Registry) FromGRPC(code GRPCCode, message string) error {
+	return r.FromStatus(Status{
+		GRPC:    code,
+		Message: message,
+	})
+}
+
+// FromStatus restores an error on the client side. The error has the code, the spec or wraps the sentinel
+// of the first registered mapping with the same HTTP or gRPC code when the HTTP one is not set.
+// The status itself is always put as a spec too, use beer.AsSpec[status.Status] to get it.
+func (r *Registry) FromStatus(st Status) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	match := func(m Mapping) bool {
+		if st.HTTP != 0 {
+			return m.HTTP == st.HTTP
+		}
+		return m.GRPC == st.GRPC
+	}
+
+	var err *beer.Error
+	for _, entry := range r.codes {
+		if match(entry.mapping) {
+			err = beer.Spec(beer.New(st.Message), entry.code)
+			break
+		}
+	}
+	if err == nil {
+		for _, entry := range r.specs {
+			if match(entry.mapping) {
+				err = beer.New(st.Message)
+				if entry.spec != nil {
+					err = beer.Spec(err, entry.spec)
+				}
+				break
+			}
+		}
+	}
+	if err == nil {
+		for _, entry := range r.sentinels {
+			if match(entry.mapping) {
+				err = beer.Wrap(entry.err, st.Message)
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = beer.New(st.Message)
+	}
+
+	for _, field := range st.Fields {
+		appendField(err, field)
+	}
+
+	return beer.Spec(err, st)
+}
+
+func appendField(err *beer.Error, field Field) {
+	switch v := field.Value.(type) {
+	case bool:
+		err.Bool(field.Key, v)
+	case time.Time:
+		err.Time(field.Key, v)
+	case time.Duration:
+		err.Duration(field.Key, v)
+	case int:
+		err.Int(field.Key, v)
+	case int8:
+		err.Int8(field.Key, v)
+	case int16:
+		err.Int16(field.Key, v)
+	case int32:
+		err.Int32(field.Key, v)
+	case int64:
+		err.Int64(field.Key, v)
+	case uint:
+		err.Uint(field.Key, v)
+	case uint8:
+		err.Uint8(field.Key, v)
+	case uint16:
+		err.Uint16(field.Key, v)
+	case uint32:
+		err.Uint32(field.Key, v)
+	case uint64:
+		err.Uint64(field.Key, v)
+	case float32:
+		err.Flt32(field.Key, v)
+	case float64:
+		err.Flt64(field.Key, v)
+	case string:
+		err.Str(field.Key, v)
+	case []byte:
+		err.Bytes(field.Key, v)
+	case []string:
+		err.Strs(field.Key, v)
+	default:
+		err.Str(field.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,88 @@
+package status_test
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirkon/blog/beer"
+	"github.com/sirkon/blog/beer/status"
+)
+
+type notFound struct{}
+
+var errThrottled = beer.NewSentinel("throttled")
+
+func ExampleRegistry() {
+	r := status.NewRegistry()
+	status.RegisterSpec[notFound](r, status.Mapping{
+		HTTP:    404,
+		GRPC:    status.GRPCNotFound,
+		Message: "account not found",
+		Fields:  []string{"account-id"},
+	})
+	r.RegisterSentinel(errThrottled, status.Mapping{
+		HTTP: 429,
+		GRPC: status.GRPCResourceExhausted,
+	})
+
+	var err error
+	err = beer.Wrap(io.EOF, "query account").Int("account-id", 42).Str("dsn", "postgres://secret")
+	err = beer.Spec(err, notFound{})
+	st := r.Status(err)
+	fmt.Println(st.HTTP, st.GRPC, st.Message, st.Fields)
+
+	rec := httptest.NewRecorder()
+	r.WriteHTTP(rec, err)
+	fmt.Println(rec.Code, rec.Body.String())
+
+	st = r.Status(beer.Wrap(errThrottled, "call backend"))
+	fmt.Println(st.HTTP, st.GRPC, st.Message)
+
+	st = r.Status(io.EOF)
+	fmt.Println(st.HTTP, st.GRPC, st.Message)
+
+	err = r.FromGRPC(status.GRPCNotFound, "account not found")
+	fmt.Println(err, beer.IsSpec[notFound](err))
+
+	err = r.FromHTTP(429, "slow down")
+	fmt.Println(err, beer.Is(err, errThrottled))
+
+	// Output:
+	// 404 NotFound account not found [{account-id 42}]
+	// 404 {"message":"account not found","fields":{"account-id":42}}
+	// 429 ResourceExhausted Too Many Requests
+	// 500 Internal internal error
+	// account not found true
+	// slow down: throttled true
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+type timeout struct{}
+
+func (timeout) Temporary() bool { return true }
+
+func TestRegisterSpecInterface(t *testing.T) {
+	r := status.NewRegistry()
+	status.RegisterSpec[temporary](r, status.Mapping{
+		HTTP: 503,
+		GRPC: status.GRPCUnavailable,
+	})
+
+	st := r.Status(beer.Spec(beer.New("query"), timeout{}))
+	if st.HTTP != 503 || st.GRPC != status.GRPCUnavailable {
+		t.Errorf("expected 503 Unavailable, got %d %s", st.HTTP, st.GRPC)
+	}
+
+	err := r.FromHTTP(503, "try later")
+	if got := r.Status(err); got.HTTP != 500 {
+		t.Errorf("errors restored for interfaces have no spec, got %d", got.HTTP)
+	}
+	if err.Error() != "try later" {
+		t.Errorf("unexpected error text %q", err)
+	}
+}
@@ -0,0 +1,37 @@
+package beer
+
+import (
+	"github.com/sirkon/blog/internal/core"
+)
+
+// WireSpec is implemented by specs that are to be shipped along with errors
+// by [MarshalBinary]. See [core.WireSpec].
+type WireSpec = core.WireSpec
+
+// MarshalBinary encodes an error to ship it to another process, in a gRPC trailer
+// or HTTP header for instance. The origin names the sender, a service typically.
+//
+// Error text, structured context and specs implementing [WireSpec] are shipped.
+func MarshalBinary(err error, origin string) ([]byte, error) {
+	return core.MarshalErrorBinary(err, origin)
+}
+
+// UnmarshalBinary restores an error encoded with [MarshalBinary]. The remote error with
+// its full context is nested into a single stage marked with its origin:
+//
+//	└─ err
+//	   ├─ @context
+//	   │  └─ REMOTE: billing
+//	   │     ├─ NEW: no funds
+//	   │     │  └─ account-id: 42
+//	   │     └─ WRAP: charge
+//	   └─ @text: charge: no funds
+func UnmarshalBinary(data []byte) (*Error, error) {
+	return core.UnmarshalErrorBinary(data)
+}
+
+// RegisterWireSpec registers a decoder for specs implementing [WireSpec] with the given name.
+// Specs having no decoder registered are dropped by [UnmarshalBinary].
+func RegisterWireSpec(name string, decode func(data []byte) (any, error)) {
+	core.RegisterWireSpec(name, decode)
+}
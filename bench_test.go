@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -23,6 +24,7 @@ var (
 	discardLogger *blog.Logger
 	blogpLogger   *blog.Logger
 	bufferLogger  *blog.Logger
+	sampledLogger *blog.Logger
 	zlogger       zerolog.Logger
 
 	blogFile           *os.File
@@ -58,6 +60,11 @@ func TestMain(t *testing.M) {
 	discardLogger, _ = blog.NewLogger(blog.NewSyncWriter(io.Discard))
 	blogpLogger, _ = blog.NewLogger(blog.NewPrettyWriter(blogTextPrettyFile))
 	bufferLogger, _ = blog.NewLogger(newBufferWriter())
+	sampledLogger, _ = blog.NewLogger(
+		newBufferWriter(),
+		blog.OptionSampling(time.Second, 100, 100),
+		blog.OptionRateLimit(blog.LevelInfo, 1000, 100),
+	)
 	zlogger = zerolog.New(newBufferWriter())
 
 	t.Run()
@@ -153,6 +160,13 @@ func BenchmarkBufferWriteCost(b *testing.B) {
 	})
 }
 
+func BenchmarkSampling(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		sampledLogger.Info(nil, "hot loop iteration", blog.Int("count", 333), blog.Str("text", "Hello World!"))
+	}
+}
+
 func BenchmarkWriteCost(b *testing.B) {
 	for b.Loop() {
 		if _, err := justFile.Write(text); err != nil {
@@ -0,0 +1,34 @@
+// Command blog-explore shows log files written by the blog.Logger in an interactive terminal explorer.
+//
+//	blog-explore [-follow] file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sirkon/blog/explorer"
+)
+
+func main() {
+	follow := flag.Bool("follow", false, "follow records appended to the file")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(), "usage: blog-explore [-follow] file")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	e := explorer.New(flag.Arg(0))
+	if *follow {
+		e.WithFollow()
+	}
+	if err := e.Run(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
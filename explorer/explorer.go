@@ -0,0 +1,355 @@
+// Package explorer implements an interactive terminal explorer of log files written by the blog.Logger.
+//
+// It shows records as a scrollable list where records, groups of attributes and stages of errors
+// can be expanded and collapsed. Records can be filtered by level, text and attributes, the time of
+// the record can be jumped to, and the file can be followed as new records are written.
+package explorer
+
+import (
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// Explorer shows records of the log file in the terminal.
+type Explorer struct {
+	path   string
+	follow bool
+	poll   time.Duration
+}
+
+// New creates an [Explorer] of the log file.
+func New(path string) *Explorer {
+	return &Explorer{
+		path: path,
+		poll: 250 * time.Millisecond,
+	}
+}
+
+// WithFollow starts the explorer in the follow mode, where the cursor stays at the last record
+// as new ones are written into the file.
+func (e *Explorer) WithFollow() *Explorer {
+	e.follow = true
+	return e
+}
+
+// WithPollInterval sets how often the file is checked for new records, it is 250ms by default.
+func (e *Explorer) WithPollInterval(d time.Duration) *Explorer {
+	e.poll = d
+	return e
+}
+
+// Run shows the explorer until the user quits it. It needs the controlling terminal
+// and works only on unix-like platforms.
+func (e *Explorer) Run() error {
+	src, err := openSource(e.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.close()
+	}()
+
+	recs, err := src.poll()
+	if err != nil {
+		return err
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = term.close()
+	}()
+
+	s := &session{
+		view: &view{
+			follow: e.follow,
+		},
+	}
+	s.view.width, s.view.height = term.size()
+	s.view.add(recs)
+	if !s.view.follow {
+		s.view.moveTo(0)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	keys := make(chan []string)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := term.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			select {
+			case keys <- parseKeys(buf[:n]):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	resize := make(chan os.Signal, 1)
+	term.notifyResize(resize)
+	broken := src.broken
+	if broken > 0 {
+		s.view.status = strconv.Itoa(broken) + " broken records skipped"
+	}
+	ticker := time.NewTicker(e.poll)
+	defer ticker.Stop()
+
+	for {
+		s.draw(term)
+
+		select {
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range pressed {
+				if s.handle(key, term) {
+					return nil
+				}
+			}
+		case <-resize:
+			s.view.width, s.view.height = term.size()
+			s.view.moveTo(s.view.cursor)
+		case <-ticker.C:
+			recs, err := src.poll()
+			if err != nil {
+				s.view.status = err.Error()
+			}
+			s.view.add(recs)
+			if src.broken > broken {
+				broken = src.broken
+				s.view.status = strconv.Itoa(broken) + " broken records skipped"
+			}
+		}
+	}
+}
+
+// session is a state of the running explorer.
+type session struct {
+	view   *view
+	prompt *prompt
+	help   bool
+	frame  strings.Builder
+}
+
+// prompt reads a line of input in the status line.
+type prompt struct {
+	label string
+	text  []rune
+	apply func(s *session, text string)
+}
+
+// handle processes the key, returns true if the explorer must quit.
+func (s *session) handle(key string, term *terminal) bool {
+	v := s.view
+	if s.prompt != nil {
+		s.handlePrompt(key)
+		return false
+	}
+	if s.help {
+		s.help = false
+		return key == "q" || key == keyCtrlC
+	}
+
+	v.status = ""
+	switch key {
+	case "q", keyCtrlC:
+		return true
+	case keyUp, "k":
+		v.follow = false
+		v.move(-1)
+	case keyDown, "j":
+		v.move(1)
+	case keyPageUp, "b":
+		v.follow = false
+		v.move(-v.pageSize())
+	case keyPageDown, " ":
+		v.move(v.pageSize())
+	case keyHome, "g":
+		v.follow = false
+		v.moveTo(0)
+	case keyEnd, "G":
+		v.moveTo(len(v.lines) - 1)
+	case keyRight, "l":
+		v.expand()
+	case keyLeft, "h":
+		v.collapse()
+	case keyEnter, keyTab:
+		v.toggle()
+	case "0", "1", "2", "3", "4", "5":
+		v.filter.level = levelKeys[key[0]-'0']
+		v.refilter()
+	case "/":
+		s.ask("text: ", v.filter.text, func(s *session, text string) {
+			s.view.filter.setText(text)
+			s.view.refilter()
+		})
+	case "a":
+		s.ask("attr (path or path=value): ", "", func(s *session, text string) {
+			s.view.filter.setAttr(text)
+			s.view.refilter()
+		})
+	case "t":
+		s.ask("time: ", "", func(s *session, text string) {
+			base := time.Now()
+			if r := s.view.currentRecord(); r != nil {
+				base = r.time
+			}
+			t, err := parseJumpTime(text, base)
+			if err != nil {
+				s.view.status = err.Error()
+				return
+			}
+			s.view.follow = false
+			if !s.view.jumpToTime(t) {
+				s.view.status = "no records at " + text + " or later"
+			}
+		})
+	case keyEscape:
+		v.filter = filter{}
+		v.refilter()
+	case "f":
+		v.follow = !v.follow
+		if v.follow {
+			v.moveTo(len(v.lines) - 1)
+		}
+	case "y":
+		r := v.currentRecord()
+		if r == nil {
+			break
+		}
+		// OSC 52 puts the text into the clipboard of the terminal.
+		_, _ = term.Write([]byte("\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(recordJSON(r))) + "\a"))
+		v.status = "record copied as JSON"
+	case "?":
+		s.help = true
+	}
+
+	return false
+}
+
+// levelKeys maps digit keys to levels of the filter.
+var levelKeys = [...]core.LoggingLevel{
+	0,
+	core.LoggingLevelTrace,
+	core.LoggingLevelDebug,
+	core.LoggingLevelInfo,
+	core.LoggingLevelWarning,
+	core.LoggingLevelError,
+}
+
+func (s *session) ask(label, text string, apply func(s *session, text string)) {
+	s.prompt = &prompt{
+		label: label,
+		text:  []rune(text),
+		apply: apply,
+	}
+}
+
+func (s *session) handlePrompt(key string) {
+	p := s.prompt
+	switch key {
+	case keyEnter:
+		s.prompt = nil
+		p.apply(s, string(p.text))
+	case keyEscape, keyCtrlC:
+		s.prompt = nil
+	case keyBackspace:
+		if len(p.text) > 0 {
+			p.text = p.text[:len(p.text)-1]
+		}
+	default:
+		if r := []rune(key); len(r) == 1 {
+			p.text = append(p.text, r[0])
+		}
+	}
+}
+
+// draw redraws the whole screen.
+func (s *session) draw(term *terminal) {
+	var lines []string
+	if s.help {
+		lines = helpLines(s.view.height)
+	} else {
+		var prompt string
+		if s.prompt != nil {
+			prompt = s.prompt.label + string(s.prompt.text) + "█"
+		}
+		lines = s.view.render(prompt)
+	}
+
+	s.frame.Reset()
+	s.frame.WriteString("\033[H")
+	for i, l := range lines {
+		if i > 0 {
+			s.frame.WriteString("\r\n")
+		}
+		s.frame.WriteString(l)
+		s.frame.WriteString("\033[K")
+	}
+	_, _ = term.Write([]byte(s.frame.String()))
+}
+
+var helpText = []string{
+	"↑ ↓ k j        move",
+	"PgUp PgDn b ␣  move by page",
+	"Home End g G   go to the first or the last record",
+	"→ l  ← h       expand, collapse or go to the parent",
+	"Enter Tab      expand or collapse",
+	"1 … 5          show records of trace … error levels and above, 0 shows all",
+	"/              filter by text",
+	"a              filter by attribute: path like request.id, or path=value",
+	"Esc            clear filters",
+	"t              jump to time: 15:04:05, 2006-01-02 15:04:05 or RFC 3339",
+	"f              follow new records",
+	"y              copy the record as JSON",
+	"q              quit",
+	"",
+	"Press any key to return.",
+}
+
+func helpLines(height int) []string {
+	res := make([]string, 0, height)
+	for i := range height {
+		if i < len(helpText) {
+			res = append(res, "  "+helpText[i])
+		} else {
+			res = append(res, "")
+		}
+	}
+
+	return res
+}
+
+// parseJumpTime parses the time to jump to. Times without dates are taken at the date of the base.
+func parseJumpTime(text string, base time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, text, base.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range []string{"15:04:05.999999999", "15:04"} {
+		t, err := time.ParseInLocation(layout, text, base.Location())
+		if err != nil {
+			continue
+		}
+		year, month, day := base.Date()
+		return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), base.Location()), nil
+	}
+
+	return time.Time{}, core.NewErrorf("invalid time %q", text)
+}
@@ -0,0 +1,184 @@
+package explorer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/beer"
+)
+
+func testRecords(t *testing.T) []byte {
+	var raw bytes.Buffer
+	logger, err := blog.NewLogger(&raw, blog.OptionLogFromLevel(blog.LevelTrace))
+	assert.NoError(t, err)
+
+	logger.Debug(context.Background(), "starting", blog.Str("mode", "fast"))
+	logger.Info(context.Background(), "request served", blog.Group("request", blog.Str("path", "/users"), blog.Ints("ids", []int{1, 2})))
+	logger.Error(context.Background(), "failed", blog.Err(beer.Wrap(beer.New("disk is full").Int("free", 0), "save")))
+
+	return raw.Bytes()
+}
+
+func testSource(t *testing.T, data []byte) *source {
+	path := filepath.Join(t.TempDir(), "log.bin")
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+	src, err := openSource(path)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = src.close()
+	})
+
+	return src
+}
+
+func TestSource(t *testing.T) {
+	data := testRecords(t)
+	src := testSource(t, data[:len(data)-3])
+
+	recs, err := src.poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recs))
+	assert.Equal(t, "starting", recs[0].message)
+	assert.Equal(t, "request served", recs[1].message)
+
+	file, err := os.OpenFile(src.file.Name(), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = file.Write(append(data[len(data)-3:], "garbage"...))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	recs, err = src.poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(recs))
+	assert.Equal(t, "failed", recs[0].message)
+	assert.Equal(t, 1, src.broken)
+}
+
+func TestRecordNodes(t *testing.T) {
+	recs, err := testSource(t, testRecords(t)).poll()
+	assert.NoError(t, err)
+
+	request := recs[1].attrs[0]
+	assert.Equal(t, "request", request.key)
+	assert.True(t, request.group)
+	assert.Equal(t, "path", request.children[0].key)
+	assert.Equal(t, "/users", request.children[0].value)
+	assert.Equal(t, "ids", request.children[1].key)
+	assert.Equal(t, "[1, 2]", request.children[1].value)
+
+	errNode := recs[2].attrs[0]
+	assert.True(t, errNode.error)
+	assert.Equal(t, "@context", errNode.children[0].key)
+	text := errNode.children[len(errNode.children)-1]
+	assert.Equal(t, "@text", text.key)
+	assert.Equal(t, "save: disk is full", text.value)
+
+	for _, r := range recs {
+		js := recordJSON(r)
+		assert.True(t, json.Valid([]byte(js)), js)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	recs, err := testSource(t, testRecords(t)).poll()
+	assert.NoError(t, err)
+
+	matched := func(f filter) []string {
+		var res []string
+		for _, r := range recs {
+			if f.match(r) {
+				res = append(res, r.message)
+			}
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"request served", "failed"}, matched(filter{level: blog.LevelInfo}))
+
+	var f filter
+	f.setText("DISK")
+	assert.Equal(t, []string{"failed"}, matched(f))
+
+	f = filter{}
+	f.setAttr("request.path=user")
+	assert.Equal(t, []string{"request served"}, matched(f))
+	f.setAttr("request.path=admin")
+	assert.Equal(t, []string(nil), matched(f))
+	f.setAttr("mode")
+	assert.Equal(t, []string{"starting"}, matched(f))
+}
+
+func TestView(t *testing.T) {
+	recs, err := testSource(t, testRecords(t)).poll()
+	assert.NoError(t, err)
+	base := recs[0].time
+	for i, r := range recs {
+		r.time = base.Add(time.Duration(i) * time.Minute)
+	}
+
+	v := &view{
+		width:  80,
+		height: 10,
+	}
+	v.add(recs)
+	assert.Equal(t, 3, len(v.lines))
+
+	v.moveTo(1)
+	v.expand()
+	assert.Equal(t, 6, len(v.lines))
+	v.move(1)
+	assert.Equal(t, "request", v.current().node.key)
+	v.collapse()
+	assert.Equal(t, 4, len(v.lines))
+	v.expand()
+	assert.Equal(t, 6, len(v.lines))
+	v.move(1)
+	v.collapse()
+	assert.Equal(t, "request", v.current().node.key, "the cursor must move to the parent")
+	v.collapse()
+	v.collapse()
+	assert.Equal(t, (*node)(nil), v.current().node)
+	v.collapse()
+	assert.Equal(t, 3, len(v.lines))
+	assert.Equal(t, 1, v.current().rec)
+
+	assert.True(t, v.jumpToTime(base.Add(90*time.Second)))
+	assert.Equal(t, 2, v.current().rec)
+	assert.False(t, v.jumpToTime(base.Add(time.Hour)))
+
+	v.filter.level = blog.LevelError
+	v.refilter()
+	assert.Equal(t, 1, len(v.lines))
+	assert.Equal(t, 2, v.current().rec)
+
+	screen := v.render("")
+	assert.Equal(t, v.height, len(screen))
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []string{"j", keyUp, keyPageDown, keyEscape}, parseKeys([]byte("j\033[A\033[6~\033")))
+	assert.Equal(t, []string{keyEnter, keyBackspace, "ы", keyCtrlC}, parseKeys([]byte("\r\x7fы\x03")))
+}
+
+func TestParseJumpTime(t *testing.T) {
+	base := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	got, err := parseJumpTime("15:04:05", base)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 6, 15, 4, 5, 0, time.UTC), got)
+
+	got, err = parseJumpTime("2023-01-02 03:04:05", base)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), got)
+
+	_, err = parseJumpTime("yesterday", base)
+	assert.Error(t, err)
+}
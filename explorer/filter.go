@@ -0,0 +1,85 @@
+package explorer
+
+import (
+	"strings"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// filter decides which records are shown.
+type filter struct {
+	// level is the least level of records shown, zero means any.
+	level core.LoggingLevel
+	// text must be a part of either the message or keys and values of attributes, case insensitive.
+	text string
+	// attr is a path of the attribute, like request.path, whose value must contain attrValue.
+	attr      []string
+	attrValue string
+}
+
+// setText sets the text records must contain.
+func (f *filter) setText(text string) {
+	f.text = strings.ToLower(text)
+}
+
+// setAttr sets the attribute filter, either "path" for records having the attribute
+// or "path=value" for ones having the attribute with the value containing the given one.
+func (f *filter) setAttr(text string) {
+	path, value, _ := strings.Cut(text, "=")
+	path = strings.TrimSpace(path)
+	f.attr = nil
+	f.attrValue = value
+	if path != "" {
+		f.attr = strings.Split(path, ".")
+	}
+}
+
+func (f *filter) active() bool {
+	return f.level > 0 || f.text != "" || len(f.attr) > 0
+}
+
+func (f *filter) match(r *record) bool {
+	if r.level < f.level {
+		return false
+	}
+	if f.text != "" && !strings.Contains(strings.ToLower(r.message), f.text) && !matchText(r.attrs, f.text) {
+		return false
+	}
+	if len(f.attr) > 0 && !matchAttr(r.attrs, f.attr, f.attrValue) {
+		return false
+	}
+
+	return true
+}
+
+func matchText(nodes []*node, text string) bool {
+	for _, n := range nodes {
+		if strings.Contains(strings.ToLower(n.key), text) || strings.Contains(strings.ToLower(n.value), text) {
+			return true
+		}
+		if matchText(n.children, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAttr(nodes []*node, path []string, value string) bool {
+	for _, n := range nodes {
+		if n.key != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			if matchAttr(n.children, path[1:], value) {
+				return true
+			}
+			continue
+		}
+		if value == "" || !n.group && strings.Contains(n.value, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package explorer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordJSON returns the record as a JSON object. Attributes follow the time, the level,
+// the location and the message.
+func recordJSON(r *record) string {
+	var buf strings.Builder
+	buf.WriteString(`{"time":`)
+	buf.WriteString(jsonString(r.time.Format(time.RFC3339Nano)))
+	buf.WriteString(`,"level":`)
+	buf.WriteString(jsonString(r.level.String()))
+	if r.file != "" {
+		buf.WriteString(`,"location":`)
+		buf.WriteString(jsonString(r.file + ":" + strconv.Itoa(r.line)))
+	}
+	buf.WriteString(`,"message":`)
+	buf.WriteString(jsonString(r.message))
+	for _, n := range r.attrs {
+		buf.WriteByte(',')
+		writeNodeJSON(&buf, n)
+	}
+	buf.WriteByte('}')
+
+	return buf.String()
+}
+
+func writeNodeJSON(buf *strings.Builder, n *node) {
+	buf.WriteString(jsonString(n.key))
+	buf.WriteByte(':')
+	if !n.group {
+		buf.WriteString(n.json)
+		return
+	}
+
+	buf.WriteByte('{')
+	for i, child := range n.children {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeNodeJSON(buf, child)
+	}
+	buf.WriteByte('}')
+}
@@ -0,0 +1,106 @@
+package explorer
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Names of special keys, other keys are reported as their characters.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdown"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyTab       = "tab"
+	keyCtrlC     = "ctrl-c"
+)
+
+// escapeKeys maps escape sequences of terminals to keys.
+var escapeKeys = []struct {
+	seq string
+	key string
+}{
+	{"\033[A", keyUp},
+	{"\033[B", keyDown},
+	{"\033[C", keyRight},
+	{"\033[D", keyLeft},
+	{"\033OA", keyUp},
+	{"\033OB", keyDown},
+	{"\033OC", keyRight},
+	{"\033OD", keyLeft},
+	{"\033[5~", keyPageUp},
+	{"\033[6~", keyPageDown},
+	{"\033[H", keyHome},
+	{"\033[F", keyEnd},
+	{"\033OH", keyHome},
+	{"\033OF", keyEnd},
+	{"\033[1~", keyHome},
+	{"\033[4~", keyEnd},
+	{"\033[7~", keyHome},
+	{"\033[8~", keyEnd},
+}
+
+// parseKeys splits the terminal input into keys. A lone escape is the escape key,
+// unknown escape sequences are dropped.
+func parseKeys(data []byte) []string {
+	var res []string
+	for len(data) > 0 {
+		switch c := data[0]; {
+		case c == '\033':
+			key, size := parseEscape(data)
+			if key != "" {
+				res = append(res, key)
+			}
+			data = data[size:]
+			continue
+		case c == '\r' || c == '\n':
+			res = append(res, keyEnter)
+		case c == '\t':
+			res = append(res, keyTab)
+		case c == 0x7F || c == 0x08:
+			res = append(res, keyBackspace)
+		case c == 0x03:
+			res = append(res, keyCtrlC)
+		case c < 0x20:
+			// Other control characters are not used.
+		default:
+			r, size := utf8.DecodeRune(data)
+			res = append(res, string(r))
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+
+	return res
+}
+
+func parseEscape(data []byte) (string, int) {
+	if len(data) == 1 || data[1] == '\033' {
+		return keyEscape, 1
+	}
+
+	for _, k := range escapeKeys {
+		if bytes.HasPrefix(data, []byte(k.seq)) {
+			return k.key, len(k.seq)
+		}
+	}
+
+	// Skip an unknown sequence: CSI ones end with a letter or ~.
+	if len(data) > 2 && (data[1] == '[' || data[1] == 'O') {
+		for i := 2; i < len(data); i++ {
+			if c := data[i]; c >= 0x40 && c <= 0x7E {
+				return "", i + 1
+			}
+		}
+	}
+
+	return keyEscape, 1
+}
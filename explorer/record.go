@@ -0,0 +1,336 @@
+package explorer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// record is a decoded log record.
+type record struct {
+	time    time.Time
+	level   core.LoggingLevel
+	file    string
+	line    int
+	message string
+	attrs   []*node
+
+	expanded bool
+}
+
+// node is an attribute of a record, either a value or a group of nodes. Groups stand
+// for groups of attributes, errors and their stages.
+type node struct {
+	key      string
+	value    string // Text of the value as the pretty writer shows it.
+	json     string // JSON of the value.
+	children []*node
+	group    bool
+	error    bool // Either an error itself or its text.
+
+	collapsed bool
+}
+
+// decodeRecord decodes the binary record.
+func decodeRecord(data []byte) (*record, error) {
+	rec := &record{}
+	d := &decoder{
+		rec: rec,
+		ctx: contextDecoder{
+			rec: rec,
+		},
+	}
+	if err := core.ProcessRecord(data, d); err != nil {
+		return nil, err
+	}
+
+	return d.rec, nil
+}
+
+// decoder builds the record from elements reported by [core.ProcessRecord].
+type decoder struct {
+	rec *record
+	ctx contextDecoder
+}
+
+// contextDecoder builds attributes of the record.
+type contextDecoder struct {
+	rec   *record
+	stack []*node
+}
+
+func (d *decoder) Time(t time.Time) {
+	d.rec.time = t
+}
+
+func (d *decoder) Level(level core.LoggingLevel) {
+	d.rec.level = level
+}
+
+func (d *decoder) Location(file []byte, line int) {
+	d.rec.file = string(file)
+	d.rec.line = line
+}
+
+func (d *decoder) Message(msg []byte) {
+	d.rec.message = string(msg)
+}
+
+func (d *decoder) ContextVisitor() core.RecordContextVisitor {
+	return &d.ctx
+}
+
+func (d *contextDecoder) add(n *node) {
+	if len(d.stack) == 0 {
+		d.rec.attrs = append(d.rec.attrs, n)
+		return
+	}
+
+	top := d.stack[len(d.stack)-1]
+	top.children = append(top.children, n)
+}
+
+func (d *contextDecoder) push(key string, isError bool) {
+	n := &node{
+		key:   key,
+		group: true,
+		error: isError,
+	}
+	d.add(n)
+	d.stack = append(d.stack, n)
+}
+
+func (d *contextDecoder) pop() {
+	d.stack = d.stack[:len(d.stack)-1]
+}
+
+func (d *contextDecoder) value(key []byte, text, js string) {
+	d.add(&node{
+		key:   string(key),
+		value: text,
+		json:  js,
+	})
+}
+
+func (d *contextDecoder) slice(key []byte, text sliceText) {
+	d.value(key, text.view, text.json)
+}
+
+func (d *contextDecoder) Bool(key []byte, value bool) {
+	text := strconv.FormatBool(value)
+	d.value(key, text, text)
+}
+
+func (d *contextDecoder) Time(key []byte, value time.Time) {
+	text := value.Format(time.RFC3339Nano)
+	d.value(key, text, jsonString(text))
+}
+
+func (d *contextDecoder) Duration(key []byte, value time.Duration) {
+	text := value.String()
+	d.value(key, text, jsonString(text))
+}
+
+func (d *contextDecoder) Int(key []byte, value int)     { d.int(key, int64(value)) }
+func (d *contextDecoder) Int8(key []byte, value int8)   { d.int(key, int64(value)) }
+func (d *contextDecoder) Int16(key []byte, value int16) { d.int(key, int64(value)) }
+func (d *contextDecoder) Int32(key []byte, value int32) { d.int(key, int64(value)) }
+func (d *contextDecoder) Int64(key []byte, value int64) { d.int(key, value) }
+
+func (d *contextDecoder) Uint(key []byte, value uint)     { d.uint(key, uint64(value)) }
+func (d *contextDecoder) Uint8(key []byte, value uint8)   { d.uint(key, uint64(value)) }
+func (d *contextDecoder) Uint16(key []byte, value uint16) { d.uint(key, uint64(value)) }
+func (d *contextDecoder) Uint32(key []byte, value uint32) { d.uint(key, uint64(value)) }
+func (d *contextDecoder) Uint64(key []byte, value uint64) { d.uint(key, value) }
+
+func (d *contextDecoder) Float32(key []byte, value float32) { d.float(key, float64(value), 32) }
+func (d *contextDecoder) Float64(key []byte, value float64) { d.float(key, value, 64) }
+
+func (d *contextDecoder) int(key []byte, value int64) {
+	text := strconv.FormatInt(value, 10)
+	d.value(key, text, text)
+}
+
+func (d *contextDecoder) uint(key []byte, value uint64) {
+	text := strconv.FormatUint(value, 10)
+	d.value(key, text, text)
+}
+
+func (d *contextDecoder) float(key []byte, value float64, bits int) {
+	text := strconv.FormatFloat(value, 'g', -1, bits)
+	d.value(key, text, jsonFloat(value, text))
+}
+
+func (d *contextDecoder) Str(key []byte, value []byte) {
+	text := string(value)
+	d.value(key, text, jsonString(text))
+}
+
+func (d *contextDecoder) Bytes(key []byte, value []byte) {
+	encoded := base64.RawStdEncoding.EncodeToString(value)
+	d.value(key, "base64."+encoded, jsonString(encoded))
+}
+
+func (d *contextDecoder) RawError(key []byte, value []byte) {
+	text := string(value)
+	d.add(&node{
+		key:   string(key),
+		value: text,
+		json:  jsonString(text),
+		error: true,
+	})
+}
+
+func (d *contextDecoder) Secret(key []byte) {
+	d.value(key, redactedMarker, jsonString(redactedMarker))
+}
+
+func (d *contextDecoder) BoolSlice(key []byte, seq []bool) {
+	d.slice(key, formatSlice(seq, strconv.FormatBool, strconv.FormatBool))
+}
+
+func (d *contextDecoder) IntSlice(key []byte, seq []int)     { d.slice(key, formatInts(seq)) }
+func (d *contextDecoder) Int8Slice(key []byte, seq []int8)   { d.slice(key, formatInts(seq)) }
+func (d *contextDecoder) Int16Slice(key []byte, seq []int16) { d.slice(key, formatInts(seq)) }
+func (d *contextDecoder) Int32Slice(key []byte, seq []int32) { d.slice(key, formatInts(seq)) }
+func (d *contextDecoder) Int64Slice(key []byte, seq []int64) { d.slice(key, formatInts(seq)) }
+
+func (d *contextDecoder) UintSlice(key []byte, seq []uint)     { d.slice(key, formatUints(seq)) }
+func (d *contextDecoder) Uint8Slice(key []byte, seq []uint8)   { d.slice(key, formatUints(seq)) }
+func (d *contextDecoder) Uint16Slice(key []byte, seq []uint16) { d.slice(key, formatUints(seq)) }
+func (d *contextDecoder) Uint32Slice(key []byte, seq []uint32) { d.slice(key, formatUints(seq)) }
+func (d *contextDecoder) Uint64Slice(key []byte, seq []uint64) { d.slice(key, formatUints(seq)) }
+
+func (d *contextDecoder) Float32Slice(key []byte, seq []float32) {
+	d.slice(key, formatFloats(seq, 32))
+}
+
+func (d *contextDecoder) Float64Slice(key []byte, seq []float64) {
+	d.slice(key, formatFloats(seq, 64))
+}
+
+func (d *contextDecoder) StrSlice(key []byte, seq [][]byte) {
+	d.slice(key, formatSlice(
+		seq,
+		func(v []byte) string { return strconv.Quote(string(v)) },
+		func(v []byte) string { return jsonString(string(v)) },
+	))
+}
+
+func (d *contextDecoder) EnterGroup(key []byte) {
+	d.push(string(key), false)
+}
+
+func (d *contextDecoder) LeaveGroup() {
+	d.pop()
+}
+
+func (d *contextDecoder) EnterError(key []byte) {
+	d.push(string(key), true)
+	d.push("@context", false)
+}
+
+func (d *contextDecoder) EnterErrorStage(state core.ErrorProcessingStage, text []byte) {
+	switch state {
+	case core.ErrorProcessingStageNew:
+		d.push("NEW: "+string(text), false)
+	case core.ErrorProcessingStageWrap:
+		d.push("WRAP: "+string(text), false)
+	case core.ErrorProcessingStageContext:
+		d.push("CTX", false)
+	case core.ErrorProcessingStageRemote:
+		d.push("REMOTE: "+string(text), false)
+	default:
+		d.push(string(text), false)
+	}
+}
+
+func (d *contextDecoder) ErrorStageLocation(file []byte, line int) {
+	text := string(file) + ":" + strconv.Itoa(line)
+	d.value([]byte("@location"), text, jsonString(text))
+}
+
+func (d *contextDecoder) LeaveErrorStage() {
+	d.pop()
+}
+
+func (d *contextDecoder) LeaveError(text []byte) {
+	// Leave @context and put the text of the error after it.
+	d.pop()
+	d.add(&node{
+		key:   "@text",
+		value: string(text),
+		json:  jsonString(string(text)),
+		error: true,
+	})
+	d.pop()
+}
+
+func (d *contextDecoder) Finish() {}
+
+// redactedMarker replaces values of secrets, just like the pretty writer does.
+const redactedMarker = "[REDACTED]"
+
+func formatInts[T int | int8 | int16 | int32 | int64](seq []T) sliceText {
+	format := func(v T) string { return strconv.FormatInt(int64(v), 10) }
+	return formatSlice(seq, format, format)
+}
+
+func formatUints[T uint | uint8 | uint16 | uint32 | uint64](seq []T) sliceText {
+	format := func(v T) string { return strconv.FormatUint(uint64(v), 10) }
+	return formatSlice(seq, format, format)
+}
+
+func formatFloats[T float32 | float64](seq []T, bits int) sliceText {
+	return formatSlice(
+		seq,
+		func(v T) string { return strconv.FormatFloat(float64(v), 'g', -1, bits) },
+		func(v T) string { return jsonFloat(float64(v), strconv.FormatFloat(float64(v), 'g', -1, bits)) },
+	)
+}
+
+// sliceText is a text of a slice for the view and for JSON.
+type sliceText struct {
+	view string
+	json string
+}
+
+func formatSlice[T any](seq []T, text, js func(T) string) sliceText {
+	var view, data strings.Builder
+	view.WriteByte('[')
+	data.WriteByte('[')
+	for i, v := range seq {
+		if i > 0 {
+			view.WriteString(", ")
+			data.WriteByte(',')
+		}
+		view.WriteString(text(v))
+		data.WriteString(js(v))
+	}
+	view.WriteByte(']')
+	data.WriteByte(']')
+
+	return sliceText{
+		view: view.String(),
+		json: data.String(),
+	}
+}
+
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// jsonFloat returns the text of the float if it is valid JSON, NaN and infinities are quoted.
+func jsonFloat(value float64, text string) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return jsonString(text)
+	}
+
+	return text
+}
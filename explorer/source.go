@@ -0,0 +1,113 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// source reads records of the log file, including ones appended after it was opened.
+type source struct {
+	file    *os.File
+	pending []byte
+	chunk   []byte
+
+	// broken counts records which were not decoded.
+	broken int
+}
+
+func openSource(path string) (*source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, core.WrapError(err, "open log file")
+	}
+
+	return &source{
+		file:  file,
+		chunk: make([]byte, 256*1024),
+	}, nil
+}
+
+func (s *source) close() error {
+	return s.file.Close()
+}
+
+// poll returns records written since the last call. An incomplete record at the end of the file
+// is kept until the rest of it is written. Broken data is skipped up to the next record.
+func (s *source) poll() ([]*record, error) {
+	for {
+		n, err := s.file.Read(s.chunk)
+		s.pending = append(s.pending, s.chunk[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, core.WrapError(err, "read log file")
+		}
+	}
+
+	var res []*record
+	rest := s.pending
+	for len(rest) > 0 {
+		size, complete := recordSize(rest)
+		if !complete {
+			break
+		}
+		if size < 0 {
+			rest = s.skipBroken(rest)
+			continue
+		}
+
+		rec, err := decodeRecord(rest[:size])
+		if err != nil {
+			rest = s.skipBroken(rest)
+			continue
+		}
+		res = append(res, rec)
+		rest = rest[size:]
+	}
+	s.pending = append(s.pending[:0], rest...)
+
+	return res, nil
+}
+
+// skipBroken skips data up to the next possible start of a record.
+func (s *source) skipBroken(data []byte) []byte {
+	s.broken++
+	for i := 1; i < len(data); i++ {
+		if data[i] == 0xFF {
+			return data[i:]
+		}
+	}
+
+	return nil
+}
+
+// recordSize returns the size of the record the data starts with. The size is negative
+// if the data does not look like a record. Complete is false if more data is needed.
+func recordSize(data []byte) (size int, complete bool) {
+	if data[0] != 0xFF {
+		return -1, true
+	}
+	if len(data) < 6 {
+		return 0, false
+	}
+
+	length, n := binary.Uvarint(data[5:])
+	switch {
+	case n == 0:
+		return 0, false
+	case n < 0 || length > 64<<20:
+		return -1, true
+	}
+
+	size = 5 + n + int(length)
+	if len(data) < size {
+		return 0, false
+	}
+
+	return size, true
+}
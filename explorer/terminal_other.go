@@ -0,0 +1,22 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package explorer
+
+import (
+	"os"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// terminal is not supported here.
+type terminal struct{}
+
+func openTerminal() (*terminal, error) {
+	return nil, core.NewError("terminal is not supported on this platform")
+}
+
+func (t *terminal) Read(p []byte) (int, error)    { return 0, nil }
+func (t *terminal) Write(p []byte) (int, error)   { return len(p), nil }
+func (t *terminal) size() (width, height int)     { return 80, 24 }
+func (t *terminal) notifyResize(chan<- os.Signal) {}
+func (t *terminal) close() error                  { return nil }
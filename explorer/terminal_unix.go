@@ -0,0 +1,86 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package explorer
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// terminal is the controlling terminal switched to the raw mode and the alternate screen.
+type terminal struct {
+	tty   *os.File
+	state *unix.Termios
+}
+
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, core.WrapError(err, "open terminal")
+	}
+
+	fd := int(tty.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		_ = tty.Close()
+		return nil, core.WrapError(err, "get terminal state")
+	}
+
+	raw := *state
+	raw.Iflag &^= unix.BRKINT | unix.ICRNL | unix.INPCK | unix.ISTRIP | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ICANON | unix.IEXTEN | unix.ISIG
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		_ = tty.Close()
+		return nil, core.WrapError(err, "switch terminal to raw mode")
+	}
+
+	// Alternate screen, hidden cursor.
+	_, _ = tty.WriteString("\033[?1049h\033[?25l")
+
+	return &terminal{
+		tty:   tty,
+		state: state,
+	}, nil
+}
+
+func (t *terminal) Read(p []byte) (int, error) {
+	return t.tty.Read(p)
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	return t.tty.Write(p)
+}
+
+// size returns the number of columns and rows of the terminal.
+func (t *terminal) size() (width, height int) {
+	size, err := unix.IoctlGetWinsize(int(t.tty.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return 80, 24
+	}
+
+	return int(size.Col), int(size.Row)
+}
+
+// notifyResize sends into the channel when the terminal is resized.
+func (t *terminal) notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, unix.SIGWINCH)
+}
+
+// close restores the terminal.
+func (t *terminal) close() error {
+	_, _ = t.tty.WriteString("\033[?25h\033[?1049l")
+	err := unix.IoctlSetTermios(int(t.tty.Fd()), ioctlSetTermios, t.state)
+	if cerr := t.tty.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,417 @@
+package explorer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// line is a line of the view: either a header of the record or one of its attributes.
+type line struct {
+	rec   int   // Index of the record.
+	node  *node // Attribute of the line, nil for headers.
+	depth int
+}
+
+// view keeps the state of the explorer screen. It knows nothing about the terminal,
+// it just renders lines of the given size.
+type view struct {
+	records []*record
+	filter  filter
+	visible []int // Indices of records passing the filter.
+	lines   []line
+
+	cursor int
+	top    int
+	width  int
+	height int // Includes the status line.
+	follow bool
+	status string
+}
+
+// add adds new records. The cursor moves to the last line in the follow mode.
+func (v *view) add(recs []*record) {
+	for _, r := range recs {
+		v.records = append(v.records, r)
+		if v.filter.match(r) {
+			v.visible = append(v.visible, len(v.records)-1)
+			v.lines = appendRecordLines(v.lines, r, len(v.records)-1)
+		}
+	}
+
+	if v.follow {
+		v.moveTo(len(v.lines) - 1)
+	}
+}
+
+// refilter applies the filter anew keeping the cursor on the same record if it is still shown.
+func (v *view) refilter() {
+	current := v.current()
+	v.visible = v.visible[:0]
+	for i, r := range v.records {
+		if v.filter.match(r) {
+			v.visible = append(v.visible, i)
+		}
+	}
+	v.relayout(current)
+}
+
+// relayout rebuilds lines after records were expanded or collapsed and puts the cursor on the given line.
+func (v *view) relayout(current line) {
+	v.lines = v.lines[:0]
+	for _, i := range v.visible {
+		v.lines = appendRecordLines(v.lines, v.records[i], i)
+	}
+
+	cursor := 0
+	for i, l := range v.lines {
+		if l.rec > current.rec {
+			break
+		}
+		cursor = i
+		if l == current {
+			break
+		}
+	}
+	v.moveTo(cursor)
+}
+
+func appendRecordLines(lines []line, r *record, index int) []line {
+	lines = append(lines, line{rec: index})
+	if r.expanded {
+		lines = appendNodeLines(lines, r.attrs, index, 0)
+	}
+
+	return lines
+}
+
+func appendNodeLines(lines []line, nodes []*node, index, depth int) []line {
+	for _, n := range nodes {
+		lines = append(lines, line{
+			rec:   index,
+			node:  n,
+			depth: depth,
+		})
+		if n.group && !n.collapsed {
+			lines = appendNodeLines(lines, n.children, index, depth+1)
+		}
+	}
+
+	return lines
+}
+
+// current returns the line under the cursor.
+func (v *view) current() line {
+	if v.cursor < 0 || v.cursor >= len(v.lines) {
+		return line{rec: -1}
+	}
+
+	return v.lines[v.cursor]
+}
+
+// currentRecord returns the record under the cursor.
+func (v *view) currentRecord() *record {
+	l := v.current()
+	if l.rec < 0 {
+		return nil
+	}
+
+	return v.records[l.rec]
+}
+
+func (v *view) pageSize() int {
+	return max(v.height-1, 1)
+}
+
+// moveTo moves the cursor to the line keeping it on the screen.
+func (v *view) moveTo(cursor int) {
+	v.cursor = min(max(cursor, 0), len(v.lines)-1)
+	page := v.pageSize()
+	if v.cursor < v.top {
+		v.top = v.cursor
+	}
+	if v.cursor >= v.top+page {
+		v.top = v.cursor - page + 1
+	}
+	v.top = max(min(v.top, len(v.lines)-page), 0)
+}
+
+func (v *view) move(delta int) {
+	v.moveTo(v.cursor + delta)
+}
+
+// expand expands the record or the group under the cursor.
+func (v *view) expand() {
+	l := v.current()
+	switch {
+	case l.rec < 0:
+		return
+	case l.node == nil:
+		v.records[l.rec].expanded = true
+	case l.node.group:
+		l.node.collapsed = false
+	default:
+		return
+	}
+	v.relayout(l)
+}
+
+// collapse collapses the record or the group under the cursor. The cursor moves to the parent
+// if there is nothing to collapse.
+func (v *view) collapse() {
+	l := v.current()
+	switch {
+	case l.rec < 0:
+		return
+	case l.node == nil:
+		v.records[l.rec].expanded = false
+	case l.node.group && !l.node.collapsed:
+		l.node.collapsed = true
+	default:
+		for i := v.cursor - 1; i >= 0; i-- {
+			if v.lines[i].rec != l.rec || v.lines[i].depth < l.depth || v.lines[i].node == nil {
+				v.moveTo(i)
+				return
+			}
+		}
+		return
+	}
+	v.relayout(l)
+}
+
+// toggle expands or collapses the record or the group under the cursor.
+func (v *view) toggle() {
+	l := v.current()
+	switch {
+	case l.rec < 0:
+	case l.node == nil && v.records[l.rec].expanded, l.node != nil && l.node.group && !l.node.collapsed:
+		v.collapse()
+	default:
+		v.expand()
+	}
+}
+
+// jumpToTime moves the cursor to the first shown record made at the given time or later.
+// Records are expected to be ordered by time, as they are in log files.
+func (v *view) jumpToTime(t time.Time) bool {
+	pos := sort.Search(len(v.lines), func(i int) bool {
+		return !v.records[v.lines[i].rec].time.Before(t)
+	})
+	if pos == len(v.lines) {
+		return false
+	}
+
+	// Go to the header of the record.
+	for pos > 0 && v.lines[pos].node != nil {
+		pos--
+	}
+	v.moveTo(pos)
+	return true
+}
+
+// render returns lines of the screen, the status line is the last one.
+func (v *view) render(prompt string) []string {
+	page := v.pageSize()
+	res := make([]string, 0, page+1)
+	for i := v.top; i < v.top+page; i++ {
+		if i >= len(v.lines) {
+			res = append(res, "")
+			continue
+		}
+		res = append(res, v.renderLine(v.lines[i], i == v.cursor))
+	}
+
+	return append(res, v.renderStatus(prompt))
+}
+
+const (
+	styleReset   = "\033[0m"
+	styleCursor  = "\033[7m"
+	styleFaint   = "\033[2m"
+	styleBold    = "\033[1m"
+	styleKey     = "\033[36m"
+	styleError   = "\033[1;31m"
+	styleLevel   = "\033[1;33m"
+	styleStatus  = "\033[7;1m"
+	styleMarkers = "\033[35m"
+)
+
+func (v *view) renderLine(l line, cursor bool) string {
+	var s segments
+	s.width = v.width
+	r := v.records[l.rec]
+
+	if l.node == nil {
+		marker := "  "
+		if len(r.attrs) > 0 {
+			marker = "▸ "
+			if r.expanded {
+				marker = "▾ "
+			}
+		}
+		s.add(marker, styleMarkers)
+		s.add(r.time.Format("2006-01-02 15:04:05.000 "), styleFaint)
+		s.add(levelText(r.level), levelStyle(r.level))
+		s.add(r.message, styleBold)
+		if !r.expanded {
+			for _, n := range r.attrs {
+				s.add("  ", "")
+				s.add(n.key, keyStyle(n))
+				s.add("=", "")
+				s.add(inlineValue(n), "")
+			}
+		}
+		return s.finish(cursor)
+	}
+
+	n := l.node
+	s.add(strings.Repeat("  ", l.depth+1), "")
+	switch {
+	case !n.group:
+		s.add("  ", "")
+	case n.collapsed:
+		s.add("▸ ", styleMarkers)
+	default:
+		s.add("▾ ", styleMarkers)
+	}
+	s.add(n.key, keyStyle(n))
+	switch {
+	case !n.group:
+		s.add(": ", "")
+		s.add(oneLine(n.value), "")
+	case len(n.children) == 0:
+		s.add(": {}", "")
+	case n.collapsed:
+		s.add(": ", "")
+		s.add(inlineValue(n), styleFaint)
+	}
+
+	return s.finish(cursor)
+}
+
+func (v *view) renderStatus(prompt string) string {
+	var s segments
+	s.width = v.width
+	if prompt != "" {
+		s.add(prompt, "")
+		return s.finish(false)
+	}
+
+	s.add(strconv.Itoa(len(v.visible)), "")
+	s.add("/", "")
+	s.add(strconv.Itoa(len(v.records)), "")
+	s.add(" records", "")
+	if v.filter.level > 0 {
+		s.add(" | level ≥ "+v.filter.level.String(), "")
+	}
+	if v.filter.text != "" {
+		s.add(" | text: "+v.filter.text, "")
+	}
+	if len(v.filter.attr) > 0 {
+		s.add(" | attr: "+strings.Join(v.filter.attr, "."), "")
+		if v.filter.attrValue != "" {
+			s.add("="+v.filter.attrValue, "")
+		}
+	}
+	if v.follow {
+		s.add(" | FOLLOW", "")
+	}
+	if v.status != "" {
+		s.add(" | "+v.status, "")
+	}
+	s.add(" | ? help", "")
+
+	return styleStatus + s.finish(false) + styleReset
+}
+
+// inlineValue renders the node on a single line, groups are rendered like {key=value …}.
+func inlineValue(n *node) string {
+	if !n.group {
+		return oneLine(n.value)
+	}
+
+	var buf strings.Builder
+	buf.WriteByte('{')
+	for i, child := range n.children {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(child.key)
+		buf.WriteByte('=')
+		buf.WriteString(inlineValue(child))
+	}
+	buf.WriteByte('}')
+
+	return buf.String()
+}
+
+// oneLine shows new lines of the text as ↵.
+func oneLine(text string) string {
+	return strings.ReplaceAll(text, "\n", "↵")
+}
+
+func keyStyle(n *node) string {
+	if n.error {
+		return styleError
+	}
+
+	return styleKey
+}
+
+func levelText(level core.LoggingLevel) string {
+	text := level.String()
+	return text + strings.Repeat(" ", max(6-len(text), 1))
+}
+
+func levelStyle(level core.LoggingLevel) string {
+	switch {
+	case level >= core.LoggingLevelError:
+		return styleError
+	case level >= core.LoggingLevelWarning:
+		return styleLevel
+	default:
+		return styleFaint
+	}
+}
+
+// segments builds a line of styled texts cut to the width.
+type segments struct {
+	buf   strings.Builder
+	width int
+	used  int
+}
+
+func (s *segments) add(text, style string) {
+	if s.used >= s.width || text == "" {
+		return
+	}
+
+	if n := utf8.RuneCountInString(text); s.used+n > s.width {
+		runes := []rune(text)
+		text = string(runes[:s.width-s.used])
+	}
+	s.used += utf8.RuneCountInString(text)
+
+	if style == "" {
+		s.buf.WriteString(text)
+		return
+	}
+	s.buf.WriteString(style)
+	s.buf.WriteString(text)
+	s.buf.WriteString(styleReset)
+}
+
+// finish returns the line padded to the width, so the cursor highlights the whole line.
+func (s *segments) finish(cursor bool) string {
+	text := s.buf.String() + strings.Repeat(" ", max(s.width-s.used, 0))
+	if !cursor {
+		return text
+	}
+
+	// Styles of segments reset the highlight, it is restored after them.
+	return styleCursor + strings.ReplaceAll(text, styleReset, styleReset+styleCursor) + styleReset
+}
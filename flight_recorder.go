@@ -0,0 +1,138 @@
+package blog
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+const (
+	flightRecorderDefaultRecords = 1024
+	flightRecorderDefaultBytes   = 1 << 20
+)
+
+// FlightRecorder keeps recent records in memory and writes them into the sink only when
+// something goes wrong: when a record of the dump level passes through it or [FlightRecorder.Dump]
+// is called. Records are kept as they are, in binary form.
+//
+// It makes logging at trace level all the time affordable:
+//
+//	logger, err := blog.NewLogger(
+//	    blog.NewFlightRecorder(file).WithMaxRecords(4096),
+//	    blog.OptionLogFromLevel(blog.LevelTrace),
+//	)
+type FlightRecorder struct {
+	lock sync.Mutex
+
+	sink       io.Writer
+	maxRecords int
+	maxBytes   int
+	dumpFrom   core.LoggingLevel
+
+	// records is a ring of kept records, buffers of its slots are reused.
+	records [][]byte
+	head    int
+	count   int
+	size    int
+}
+
+// NewFlightRecorder creates a [FlightRecorder] keeping up to 1024 last records of 1MiB total
+// and dumping them into the sink on records of [LevelError] and further.
+func NewFlightRecorder(sink io.Writer) *FlightRecorder {
+	return &FlightRecorder{
+		sink:       sink,
+		maxRecords: flightRecorderDefaultRecords,
+		maxBytes:   flightRecorderDefaultBytes,
+		dumpFrom:   core.LoggingLevelError,
+		records:    make([][]byte, flightRecorderDefaultRecords),
+	}
+}
+
+// WithMaxRecords sets how many last records are kept.
+func (r *FlightRecorder) WithMaxRecords(n int) *FlightRecorder {
+	n = max(n, 1)
+	r.maxRecords = n
+	r.records = make([][]byte, n)
+	r.head, r.count, r.size = 0, 0, 0
+	return r
+}
+
+// WithMaxBytes sets how many bytes of last records are kept. The last record is kept
+// even if it is larger.
+func (r *FlightRecorder) WithMaxBytes(n int) *FlightRecorder {
+	r.maxBytes = n
+	return r
+}
+
+// WithDumpLevel sets a level of records triggering dumps.
+func (r *FlightRecorder) WithDumpLevel(level core.LoggingLevel) *FlightRecorder {
+	r.dumpFrom = level
+	return r
+}
+
+// Write keeps the record. Records of the dump level are written into the sink right
+// after the kept ones.
+func (r *FlightRecorder) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if level, ok := core.RecordLevel(p); ok && level >= r.dumpFrom {
+		err := r.dump()
+		if _, werr := r.sink.Write(p); werr != nil {
+			err = errors.Join(err, werr)
+		}
+		return len(p), err
+	}
+
+	r.keep(p)
+	return len(p), nil
+}
+
+// Dump writes kept records into the sink and forgets them.
+func (r *FlightRecorder) Dump() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.dump()
+}
+
+// Sync syncs the sink if it has Sync() error method, like [os.File] does.
+// Kept records are not dumped.
+func (r *FlightRecorder) Sync() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if ws, ok := r.sink.(interface{ Sync() error }); ok {
+		return ws.Sync()
+	}
+
+	return nil
+}
+
+func (r *FlightRecorder) keep(p []byte) {
+	for r.count > 0 && (r.count == r.maxRecords || r.size+len(p) > r.maxBytes) {
+		r.size -= len(r.records[r.head])
+		r.head = (r.head + 1) % r.maxRecords
+		r.count--
+	}
+
+	i := (r.head + r.count) % r.maxRecords
+	r.records[i] = append(r.records[i][:0], p...)
+	r.count++
+	r.size += len(p)
+}
+
+func (r *FlightRecorder) dump() error {
+	var errs []error
+	for n := range r.count {
+		record := r.records[(r.head+n)%r.maxRecords]
+		if _, err := r.sink.Write(record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.head, r.count, r.size = 0, 0, 0
+
+	return errors.Join(errs...)
+}
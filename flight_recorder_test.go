@@ -0,0 +1,58 @@
+package blog
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewFlightRecorder(NewPrettyWriter(&buf)).WithMaxRecords(3)
+	logger, err := NewLogger(recorder, OptionLogFromLevel(LevelTrace))
+	assert.NoError(t, err)
+
+	for i := range 5 {
+		logger.Trace(context.Background(), "step", Int("i", i))
+	}
+	assert.Equal(t, "", buf.String(), "records must be kept until something goes wrong")
+
+	logger.Error(context.Background(), "failed")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 4, len(lines), buf.String())
+	for n, i := range []int{2, 3, 4} {
+		assert.Contains(t, lines[n], `"i": `+strconv.Itoa(i))
+	}
+	assert.Contains(t, lines[3], "failed")
+
+	buf.Reset()
+	logger.Debug(context.Background(), "after")
+	assert.Equal(t, "", buf.String())
+	assert.NoError(t, recorder.Dump())
+	assert.Contains(t, buf.String(), "after")
+
+	buf.Reset()
+	assert.NoError(t, recorder.Dump())
+	assert.Equal(t, "", buf.String(), "dumped records must be forgotten")
+}
+
+func TestFlightRecorderMaxBytes(t *testing.T) {
+	var raw bytes.Buffer
+	recorder := NewFlightRecorder(&raw).WithMaxRecords(100).WithMaxBytes(1)
+	logger, err := NewLogger(recorder)
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "first")
+	logger.Info(context.Background(), "second")
+	assert.NoError(t, recorder.Dump())
+
+	var out bytes.Buffer
+	_, err = NewPrettyWriter(&out).Write(raw.Bytes())
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "first")
+	assert.Contains(t, out.String(), "second")
+}
@@ -4,13 +4,13 @@ go 1.26
 
 require (
 	github.com/alecthomas/assert/v2 v2.11.0
+	github.com/mattn/go-isatty v0.0.20
 	github.com/rs/zerolog v1.34.0
+	golang.org/x/sys v0.12.0
 )
 
 require (
 	github.com/alecthomas/repr v0.4.0 // indirect
 	github.com/hexops/gotextdiff v1.0.3 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.12.0 // indirect
 )
@@ -217,6 +217,27 @@ func Str(key string, value string) Attr {
 	}
 }
 
+// predefinedStr returns an [Attr] for string with a predefined key, see [PredefinedKeys].
+func predefinedStr(name ValueKind, value string) Attr {
+	return Attr{
+		Value: Value{
+			num: uint64(len(value)),
+			srl: (*stringPtr)(unsafe.Pointer(unsafe.StringData(value))),
+		},
+		kind: ValueKindString | name,
+	}
+}
+
+// Secret returns an [Attr] for a value that must never be logged, like a password or a token.
+// The value is dropped right away, only the key gets into the record.
+func Secret(key string, value string) Attr {
+	_ = key[0]
+	return Attr{
+		Key:  key,
+		kind: ValueKindSecret,
+	}
+}
+
 // Stg returns an [Attr] for [fmt.Stringer] spec packed as just a string.
 func Stg(key string, value fmt.Stringer) Attr {
 	_ = key[0]
@@ -13,28 +13,12 @@ const maxKeyLimit = 16 * 1024 * 1024
 func AppendSerialized(src []byte, attr Attr) []byte {
 	kind := attr.kind & 0xff
 
-	src = append(src, byte(kind))
-
 	switch attr.kind {
 	case ValueKindJustContextNode, ValueKindJustContextInheritedNode, ValueKindPhantomContextNode:
-		return src
+		return append(src, byte(kind))
 	}
 
-	// Save a key.
-	knownKey := attr.kind >> 8
-	if knownKey == 0 {
-		// String key.
-		key := attr.Key
-		if len(key) > maxKeyLimit {
-			key = key[:maxKeyLimit]
-		}
-		src = binary.AppendUvarint(src, uint64(len(key)))
-		src = append(src, key...)
-	} else {
-		// Known registered key.
-		src = append(src, 0)
-		src = binary.AppendUvarint(src, uint64(knownKey))
-	}
+	src = appendSerializedHead(src, attr)
 
 	// Append core spec. Will not write anything if this is an unsupported spec.
 	switch kind {
@@ -160,3 +144,25 @@ func AppendSerialized(src []byte, attr Attr) []byte {
 
 	return src
 }
+
+// appendSerializedHead appends a kind and a key of the attr.
+func appendSerializedHead(src []byte, attr Attr) []byte {
+	src = append(src, byte(attr.kind&0xff))
+
+	knownKey := attr.kind >> 8
+	if knownKey == 0 {
+		// String key.
+		key := attr.Key
+		if len(key) > maxKeyLimit {
+			key = key[:maxKeyLimit]
+		}
+		src = binary.AppendUvarint(src, uint64(len(key)))
+		src = append(src, key...)
+	} else {
+		// Known registered key.
+		src = append(src, 0)
+		src = binary.AppendUvarint(src, uint64(knownKey))
+	}
+
+	return src
+}
@@ -23,6 +23,7 @@ const (
 	ValueKindError                    ValueKind = 10
 	ValueKindErrorEmbed               ValueKind = 11
 	ValueKindGroupEnd                 ValueKind = 12
+	ValueKindRemoteNode               ValueKind = 13
 
 	// --- Group 2: Payload / base types (32+) ---
 
@@ -46,6 +47,7 @@ const (
 	ValueKindString   ValueKind = 49
 	ValueKindBytes    ValueKind = 50
 	ValueKindErrorRaw ValueKind = 51
+	ValueKindSecret   ValueKind = 52 // Has no value at all, see [Secret].
 
 	// --- Group 3: Slices (64+) ---
 
@@ -69,9 +71,13 @@ const (
 	// There're ValueKind values at 256 and further to represent [Attr] with predefined keys, where their
 	// lowest byte represents a kind and the upper 7 bytes refer a key index.
 
-	ValuePredefinedNameContext  = 1 << 8
-	ValuePredefinedNameText     = 2 << 8
-	ValuePredefinedNameLocation = 3 << 8
+	ValuePredefinedNameContext     = 1 << 8
+	ValuePredefinedNameText        = 2 << 8
+	ValuePredefinedNameLocation    = 3 << 8
+	ValuePredefinedNameFingerprint = 4 << 8
+	ValuePredefinedNameCode        = 5 << 8
+	ValuePredefinedNameComponent   = 6 << 8
+	ValuePredefinedNameStack       = 7 << 8
 )
 
 func (k ValueKind) String() string {
@@ -100,6 +106,8 @@ func (k ValueKind) String() string {
 		return "ForeignWrap(beer.Error)"
 	case ValueKindGroupEnd:
 		return "group.end"
+	case ValueKindRemoteNode:
+		return "RemoteNode"
 	case ValueKindBool:
 		return "bool"
 	case ValueKindTime:
@@ -136,6 +144,8 @@ func (k ValueKind) String() string {
 		return "[]byte"
 	case ValueKindErrorRaw:
 		return "error"
+	case ValueKindSecret:
+		return "secret"
 	case ValueKindSliceBool:
 		return "[]bool"
 	case ValueKindSliceInt:
@@ -180,4 +190,8 @@ var PredefinedKeys = []string{
 	"@context",
 	"@text",
 	"@location",
+	"@fingerprint",
+	"@code",
+	"@component",
+	"@stack",
 }
@@ -1,7 +1,6 @@
 package core
 
 import (
-	"encoding/binary"
 	"errors"
 	"strings"
 )
@@ -32,89 +31,34 @@ func (e *Error) Error() string {
 
 loop:
 	for len(payload) > 0 {
-		kind := ValueKind(payload[0])
-		payload = payload[1:]
+		var kind ValueKind
+		var key, value []byte
+		kind, key, value, payload = splitPayloadNode(payload)
 		switch kind {
-		case ValueKindJustContextNode:
-			continue
 		case ValueKindPhantomContextNode:
 			if !e.sufficient {
 				nodes = append(nodes, nil)
 				strInsert = e.wrap.Error()
 			}
-			continue
 		case ValueKindJustContextInheritedNode:
 			if !e.sufficient {
 				nodes = append(nodes, nil)
 				strInsert = e.wrap.Error()
 			}
 			break loop
-		case ValueKindGroupEnd:
-			continue
-		}
-
-		var key []byte
-		length, varintLength := binary.Uvarint(payload)
-		if length > 0 {
-			key = payload[varintLength : varintLength+int(length)]
-		}
-		payload = payload[varintLength+int(length):]
-		switch kind {
-		case ValueKindNewNode, ValueKindWrapNode:
+		case ValueKindNewNode, ValueKindWrapNode, ValueKindForeignErrorText:
 			nodes = append(nodes, key)
-
 		case ValueKindWrapInheritedNode:
 			if !e.sufficient {
 				nodes = append(nodes, nil)
 				strInsert = e.wrap.Error()
-				nodes = append(nodes, key)
-			} else {
-				nodes = append(nodes, key)
 			}
-		case ValueKindForeignErrorText:
 			nodes = append(nodes, key)
-
-		case ValueKindLocationNode:
-			_, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
-
-		case ValueKindBool, ValueKindInt8, ValueKindUint8:
-			payload = payload[1:]
-		case ValueKindInt16, ValueKindUint16:
-			payload = payload[2:]
-		case ValueKindInt32, ValueKindUint32, ValueKindFloat32:
-			payload = payload[4:]
-		case ValueKindTime, ValueKindDuration,
-			ValueKindInt, ValueKindInt64,
-			ValueKindUint, ValueKindUint64,
-			ValueKindFloat64:
-			payload = payload[8:]
-		case ValueKindString, ValueKindBytes:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength+int(length):]
-		case ValueKindSliceBool, ValueKindSliceInt8, ValueKindSliceUint8:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength+int(length):]
-		case ValueKindSliceInt16, ValueKindSliceUint16:
-			payload = payload[varintLength+2*int(length):]
-		case ValueKindSliceInt32, ValueKindSliceUint32, ValueKindSliceFloat32:
-			payload = payload[varintLength+4*int(length):]
-		case ValueKindSliceInt, ValueKindSliceInt64,
-			ValueKindSliceUint, ValueKindSliceUint64,
-			ValueKindSliceFloat64:
-			payload = payload[varintLength+8*int(length):]
-		case ValueKindSliceString:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
-			for range length {
-				l, vl := binary.Uvarint(payload)
-				payload = payload[vl+int(l):]
-			}
-		case ValueKindGroup:
-			// JustError need to pass the length of group. The group itself
-			// is just a regular sequence of attributes, this loop will deal with it itself.
-			_, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
+		case ValueKindRemoteNode:
+			// Remote error text is all we need, its payload is only for the context.
+			text, nested := splitRemoteNodeValue(value)
+			nodes = append(nodes, text)
+			payload = payload[nested:]
 		}
 	}
 
@@ -141,75 +85,18 @@ func SufficientErrorBytes(payload []byte) []byte {
 
 	var totalLen int
 	for len(payload) > 0 {
-		kind := ValueKind(payload[0])
-		payload = payload[1:]
+		var kind ValueKind
+		var key, value []byte
+		kind, key, value, payload = splitPayloadNode(payload)
 		switch kind {
-		case ValueKindJustContextNode, ValueKindPhantomContextNode:
-			continue
-		case ValueKindJustContextInheritedNode:
-			break
-		}
-
-		var key []byte
-		length, varintLength := binary.Uvarint(payload)
-		if length > 0 {
-			key = payload[varintLength : varintLength+int(length)]
-		}
-		payload = payload[varintLength+int(length):]
-		switch kind {
-		case ValueKindNewNode, ValueKindWrapNode:
-			nodes = append(nodes, key)
-			totalLen += len(key)
-
-		case ValueKindWrapInheritedNode:
+		case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode, ValueKindForeignErrorText:
 			nodes = append(nodes, key)
 			totalLen += len(key)
-
-		case ValueKindForeignErrorText:
-			nodes = append(nodes, key)
-			totalLen += len(key)
-
-		case ValueKindLocationNode:
-			_, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
-
-		case ValueKindBool, ValueKindInt8, ValueKindUint8:
-			payload = payload[1:]
-		case ValueKindInt16, ValueKindUint16:
-			payload = payload[2:]
-		case ValueKindInt32, ValueKindUint32, ValueKindFloat32:
-			payload = payload[4:]
-		case ValueKindTime, ValueKindDuration,
-			ValueKindInt, ValueKindInt64,
-			ValueKindUint, ValueKindUint64,
-			ValueKindFloat64:
-			payload = payload[8:]
-		case ValueKindString, ValueKindBytes:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength+int(length):]
-		case ValueKindSliceBool, ValueKindSliceInt8, ValueKindSliceUint8:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength+int(length):]
-		case ValueKindSliceInt16, ValueKindSliceUint16:
-			payload = payload[varintLength+2*int(length):]
-		case ValueKindSliceInt32, ValueKindSliceUint32, ValueKindSliceFloat32:
-			payload = payload[varintLength+4*int(length):]
-		case ValueKindSliceInt, ValueKindSliceInt64,
-			ValueKindSliceUint, ValueKindSliceUint64,
-			ValueKindSliceFloat64:
-			payload = payload[varintLength+8*int(length):]
-		case ValueKindSliceString:
-			length, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
-			for range length {
-				l, vl := binary.Uvarint(payload)
-				payload = payload[vl+int(l):]
-			}
-		case ValueKindGroup:
-			// JustError need to pass the length of group. The group itself
-			// is just a regular sequence of attributes, this loop will deal with it itself.
-			_, varintLength = binary.Uvarint(payload)
-			payload = payload[varintLength:]
+		case ValueKindRemoteNode:
+			text, nested := splitRemoteNodeValue(value)
+			nodes = append(nodes, text)
+			totalLen += len(text)
+			payload = payload[nested:]
 		}
 	}
 
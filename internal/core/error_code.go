@@ -0,0 +1,142 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+const errorCodeWireSpecName = "blog/error-code"
+
+// ErrorCode is a stable documented code of an error. Codes are defined with [DefineErrorCode]
+// and put on errors with [Spec], where they are also written into the error context
+// with the @code key.
+//
+// Codes are compared by their pointers, use [IsErrorCode] to check if an error has the code.
+type ErrorCode struct {
+	name        string
+	description string
+}
+
+// ErrorCodeOption sets up an optional property of [ErrorCode].
+type ErrorCodeOption interface {
+	apply(c *ErrorCode)
+}
+
+// ErrorCodeDescription sets a human readable description of the code for error catalogs.
+func ErrorCodeDescription(text string) ErrorCodeOption {
+	return errorCodeDescription(text)
+}
+
+type errorCodeDescription string
+
+func (d errorCodeDescription) apply(c *ErrorCode) {
+	c.description = string(d)
+}
+
+var errorCodes struct {
+	lock  sync.RWMutex
+	codes map[string]*ErrorCode
+}
+
+// DefineErrorCode defines a code with the given name. Names must be unique and nonempty,
+// it panics otherwise. Codes are meant to be defined as package level variables:
+//
+//	var ErrCodeDBTimeout = core.DefineErrorCode("DB_TIMEOUT")
+func DefineErrorCode(name string, options ...ErrorCodeOption) *ErrorCode {
+	if name == "" {
+		panic("error code name must not be empty")
+	}
+
+	res := &ErrorCode{
+		name: name,
+	}
+	for _, option := range options {
+		option.apply(res)
+	}
+
+	errorCodes.lock.Lock()
+	defer errorCodes.lock.Unlock()
+
+	if errorCodes.codes == nil {
+		errorCodes.codes = map[string]*ErrorCode{}
+	}
+	if _, ok := errorCodes.codes[name]; ok {
+		panic("error code " + name + " is already defined")
+	}
+	errorCodes.codes[name] = res
+
+	return res
+}
+
+// ErrorCodes returns all defined codes sorted by their names.
+func ErrorCodes() []*ErrorCode {
+	errorCodes.lock.RLock()
+	defer errorCodes.lock.RUnlock()
+
+	res := make([]*ErrorCode, 0, len(errorCodes.codes))
+	for _, code := range errorCodes.codes {
+		res = append(res, code)
+	}
+	slices.SortFunc(res, func(a, b *ErrorCode) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return res
+}
+
+// LookupErrorCode returns the defined code with the given name.
+func LookupErrorCode(name string) (*ErrorCode, bool) {
+	errorCodes.lock.RLock()
+	defer errorCodes.lock.RUnlock()
+
+	res, ok := errorCodes.codes[name]
+	return res, ok
+}
+
+// IsErrorCode checks if the code was put on the err.
+func IsErrorCode(err error, code *ErrorCode) bool {
+	for spec := range allSpecs(err) {
+		if spec == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name returns the name of the code.
+func (c *ErrorCode) Name() string {
+	return c.name
+}
+
+// Description returns the description of the code.
+func (c *ErrorCode) Description() string {
+	return c.description
+}
+
+func (c *ErrorCode) String() string {
+	return c.name
+}
+
+// WireSpecName to implement [WireSpec].
+func (c *ErrorCode) WireSpecName() string {
+	return errorCodeWireSpecName
+}
+
+// AppendWireSpec to implement [WireSpec].
+func (c *ErrorCode) AppendWireSpec(dst []byte) []byte {
+	return append(dst, c.name...)
+}
+
+func init() {
+	// Codes are restored into the same values in receiving processes. Codes not defined there
+	// are restored as well, just not being registered.
+	RegisterWireSpec(errorCodeWireSpecName, func(data []byte) (any, error) {
+		if code, ok := LookupErrorCode(string(data)); ok {
+			return code, nil
+		}
+
+		return &ErrorCode{name: string(data)}, nil
+	})
+}
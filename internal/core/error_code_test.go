@@ -0,0 +1,97 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+var (
+	codeDBTimeout = core.DefineErrorCode("DB_TIMEOUT", core.ErrorCodeDescription("database query timed out"))
+	codeNoFunds   = core.DefineErrorCode("NO_FUNDS")
+)
+
+func ExampleDefineErrorCode() {
+	err := core.Spec(core.WrapError(io.EOF, "query accounts"), codeDBTimeout)
+	code, _ := core.AsSpec[*core.ErrorCode](err)
+	fmt.Println(err, "|", code, core.IsErrorCode(err, codeDBTimeout), core.IsErrorCode(err, codeNoFunds))
+
+	for _, code := range core.ErrorCodes() {
+		if code == codeDBTimeout || code == codeNoFunds {
+			fmt.Printf("%s: %q\n", code.Name(), code.Description())
+		}
+	}
+
+	// Output:
+	// query accounts: EOF | DB_TIMEOUT true false
+	// DB_TIMEOUT: "database query timed out"
+	// NO_FUNDS: ""
+}
+
+func TestErrorCodeLogging(t *testing.T) {
+	core.InsertLocationsOff()
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Error(context.Background(), "failed", blog.Err(core.Spec(io.EOF, codeDBTimeout).Int("attempt", 3)))
+	logger.Error(context.Background(), "failed", blog.Err(core.Spec(core.NewError("no funds"), codeNoFunds)))
+
+	for _, want := range []string{
+		"@code: DB_TIMEOUT",
+		"attempt: 3",
+		"@text: EOF",
+		"@code: NO_FUNDS",
+		"@text: no funds",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in the output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestErrorCodeForeignText(t *testing.T) {
+	err := core.Spec(io.EOF, codeDBTimeout)
+	if got := err.Error(); got != "EOF" {
+		t.Errorf("unexpected error text %q", got)
+	}
+	if got := core.WrapError(err, "query").Error(); got != "query: EOF" {
+		t.Errorf("unexpected error text %q", got)
+	}
+}
+
+func TestErrorCodeWire(t *testing.T) {
+	data, err := core.MarshalErrorBinary(core.Spec(io.EOF, codeDBTimeout), "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := core.UnmarshalErrorBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !core.IsErrorCode(remote, codeDBTimeout) {
+		t.Error("the code must survive the wire")
+	}
+	if got := remote.Error(); got != "EOF" {
+		t.Errorf("unexpected error text %q", got)
+	}
+}
+
+func TestDefineErrorCodeDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("a duplicate code must panic")
+		}
+	}()
+
+	core.DefineErrorCode("DB_TIMEOUT")
+}
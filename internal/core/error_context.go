@@ -0,0 +1,127 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math"
+	"slices"
+	"time"
+	"unsafe"
+)
+
+// Specs iterates over specs of the error chain, from the most recent to the oldest.
+func Specs(err error) iter.Seq[any] {
+	return allSpecs(err)
+}
+
+// ErrorContext iterates over context values of the error stored with given keys.
+// Values of earlier stages come first, so the last value of a key is the most recent one.
+//
+// Only values set on stages themselves are matched, keys of groups and of nested errors are not,
+// even if their names are the same. Only scalar values, strings, bytes and slices of strings are reported.
+func ErrorContext(err error, keys ...string) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		e, ok := err.(*Error)
+		if !ok {
+			e, ok = errors.AsType[*Error](err)
+		}
+		if !ok {
+			return
+		}
+
+		// Open levels of the payload, see openPayloadStages, true ones are groups and nested errors.
+		var levels []bool
+		var nested int
+		payload := e.payload
+		for len(payload) > 0 {
+			var kind ValueKind
+			var key, value []byte
+			kind, key, value, payload = splitPayloadNode(payload)
+			switch kind {
+			case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+				ValueKindJustContextNode, ValueKindJustContextInheritedNode, ValueKindRemoteNode:
+				levels = append(levels, false)
+				continue
+			case ValueKindGroup, ValueKindError, ValueKindErrorEmbed:
+				levels = append(levels, true)
+				nested++
+				continue
+			case ValueKindGroupEnd:
+				if len(levels) > 0 {
+					if levels[len(levels)-1] {
+						nested--
+					}
+					levels = levels[:len(levels)-1]
+				}
+				continue
+			}
+			if nested > 0 || len(key) == 0 || isStageNodeKind(kind) {
+				continue
+			}
+			if !slices.Contains(keys, unsafe.String(unsafe.SliceData(key), len(key))) {
+				continue
+			}
+
+			v, ok := decodePayloadValue(kind, value)
+			if !ok {
+				continue
+			}
+			if !yield(string(key), v) {
+				return
+			}
+		}
+	}
+}
+
+func decodePayloadValue(kind ValueKind, value []byte) (any, bool) {
+	switch kind {
+	case ValueKindBool:
+		return value[0] != 0, true
+	case ValueKindTime:
+		return time.Unix(0, int64(binary.LittleEndian.Uint64(value))), true
+	case ValueKindDuration:
+		return time.Duration(binary.LittleEndian.Uint64(value)), true
+	case ValueKindInt:
+		return int(binary.LittleEndian.Uint64(value)), true
+	case ValueKindInt8:
+		return int8(value[0]), true
+	case ValueKindInt16:
+		return int16(binary.LittleEndian.Uint16(value)), true
+	case ValueKindInt32:
+		return int32(binary.LittleEndian.Uint32(value)), true
+	case ValueKindInt64:
+		return int64(binary.LittleEndian.Uint64(value)), true
+	case ValueKindUint:
+		return uint(binary.LittleEndian.Uint64(value)), true
+	case ValueKindUint8:
+		return value[0], true
+	case ValueKindUint16:
+		return binary.LittleEndian.Uint16(value), true
+	case ValueKindUint32:
+		return binary.LittleEndian.Uint32(value), true
+	case ValueKindUint64:
+		return binary.LittleEndian.Uint64(value), true
+	case ValueKindFloat32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(value)), true
+	case ValueKindFloat64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(value)), true
+	case ValueKindString:
+		v, _ := mustReadString(value)
+		return string(v), true
+	case ValueKindBytes:
+		v, _ := mustReadString(value)
+		return slices.Clone(v), true
+	case ValueKindSliceString:
+		length, rest := mustReadUvarint(value)
+		res := make([]string, length)
+		for i := range length {
+			var v []byte
+			v, rest = mustReadString(rest)
+			res[i] = string(v)
+		}
+		return res, true
+	default:
+		return nil, false
+	}
+}
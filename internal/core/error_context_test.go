@@ -0,0 +1,34 @@
+package core_test
+
+import (
+	"io"
+	"maps"
+	"testing"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestErrorContext(t *testing.T) {
+	inner := core.NewError("inner").Int("id", 1).Str("password", "secret")
+	err := core.WrapError(io.EOF, "query").
+		Int("id", 2).
+		Insert(core.Group("user", core.Int("id", 3), core.Str("password", "secret"))).
+		Insert(core.Err(inner))
+	err = core.WrapError(err, "handle").Str("password", "top").Int("id", 4)
+
+	var ids []int
+	for key, value := range core.ErrorContext(err, "id") {
+		if key != "id" {
+			t.Fatalf("unexpected key %q", key)
+		}
+		ids = append(ids, value.(int))
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 4 {
+		t.Errorf("ids = %v, want [2 4]", ids)
+	}
+
+	got := maps.Collect(core.ErrorContext(err, "password"))
+	if len(got) != 1 || got["password"] != "top" {
+		t.Errorf("passwords = %v, want only the top one", got)
+	}
+}
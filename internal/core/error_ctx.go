@@ -69,6 +69,10 @@ func (e *Error) Str(key string, value string) *Error {
 	return e.insert(Str(key, value))
 }
 
+func (e *Error) Secret(key string, value string) *Error {
+	return e.insert(Secret(key, value))
+}
+
 func (e *Error) Stg(key string, value fmt.Stringer) *Error {
 	return e.insert(Stg(key, value))
 }
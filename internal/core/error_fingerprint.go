@@ -0,0 +1,152 @@
+package core
+
+import (
+	"errors"
+	"path"
+	"unsafe"
+)
+
+const (
+	fingerprintOffset = 14695981039346656037
+	fingerprintPrime  = 1099511628211
+)
+
+// ErrorFingerprint computes a stable hash of the error to group identical errors together.
+//
+// The hash is computed from the chain of stage kinds, their messages and locations stored in
+// the payload. Context values are ignored, so errors differing in them only have the same
+// fingerprint. The text is hashed for errors that are not *[Error].
+//
+// Messages and texts are normalized, so values formatted into them do not split groups: words
+// with digits, like 42, 0x1f or UUIDs, and quoted values are hashed as placeholders. Locations
+// are hashed with the directory and the name of the file only, so they do not depend on where
+// the code was built.
+func ErrorFingerprint(err error) uint64 {
+	e, ok := err.(*Error)
+	if !ok {
+		e, ok = errors.AsType[*Error](err)
+	}
+	if !ok {
+		return fingerprintAppendText(fingerprintOffset, err.Error())
+	}
+
+	hash := uint64(fingerprintOffset)
+	payload := e.payload
+	for len(payload) > 0 {
+		var kind ValueKind
+		var key, value []byte
+		kind, key, value, payload = splitPayloadNode(payload)
+		switch kind {
+		case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+			ValueKindJustContextNode, ValueKindJustContextInheritedNode,
+			ValueKindForeignErrorText:
+			hash = fingerprintAppendByte(hash, byte(kind))
+			hash = fingerprintAppendText(hash, key)
+		case ValueKindRemoteNode:
+			// Remote nodes are hashed with their origins, their texts are
+			// covered with stages of remote payloads.
+			hash = fingerprintAppendByte(hash, byte(kind))
+			hash = fingerprintAppend(hash, key)
+		case ValueKindLocationNode:
+			hash = fingerprintAppendByte(hash, byte(kind))
+			hash = fingerprintAppend(hash, fingerprintLocation(unsafe.String(unsafe.SliceData(key), len(key))))
+			hash = fingerprintAppend(hash, value)
+		}
+	}
+
+	return hash
+}
+
+func fingerprintAppend[T string | []byte](hash uint64, data T) uint64 {
+	for i := range len(data) {
+		hash = fingerprintAppendByte(hash, data[i])
+	}
+	// Separate pieces, so "ab"+"c" and "a"+"bc" differ.
+	return fingerprintAppendByte(hash, 0)
+}
+
+// fingerprintAppendText hashes the normalized text: words with digits are replaced with #
+// and quoted values with "".
+func fingerprintAppendText[T string | []byte](hash uint64, text T) uint64 {
+	for i := 0; i < len(text); {
+		c := text[i]
+		if isFingerprintQuote(c) && (i == 0 || !isFingerprintWordByte(text[i-1])) {
+			end := i + 1
+			for end < len(text) && text[end] != c {
+				if text[end] == '\\' {
+					// Skip escaped characters of %q values.
+					end++
+				}
+				end++
+			}
+			if end < len(text) && text[end] == c {
+				hash = fingerprintAppendByte(hash, '"')
+				hash = fingerprintAppendByte(hash, '"')
+				i = end + 1
+				continue
+			}
+		}
+		if !isFingerprintWordByte(c) {
+			hash = fingerprintAppendByte(hash, c)
+			i++
+			continue
+		}
+
+		end := i
+		var digits bool
+		for end < len(text) && isFingerprintWordByte(text[end]) {
+			digits = digits || text[end] >= '0' && text[end] <= '9'
+			end++
+		}
+		if digits {
+			hash = fingerprintAppendByte(hash, '#')
+		} else {
+			for ; i < end; i++ {
+				hash = fingerprintAppendByte(hash, text[i])
+			}
+		}
+		i = end
+	}
+
+	return fingerprintAppendByte(hash, 0)
+}
+
+func isFingerprintQuote(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
+
+func isFingerprintWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-'
+}
+
+// fingerprintLocation cuts the file path to its directory and name, like core/error.go.
+func fingerprintLocation(file string) string {
+	dir, name := path.Split(file)
+	return path.Join(path.Base(dir), name)
+}
+
+func fingerprintAppendByte(hash uint64, b byte) uint64 {
+	hash ^= uint64(b)
+	hash *= fingerprintPrime
+	return hash
+}
+
+// appendErrorFingerprint puts a fingerprint into a just serialized error attribute,
+// right before its closing [ValueKindGroupEnd]. Does nothing for other attributes.
+func appendErrorFingerprint(src []byte, attr Attr) []byte {
+	switch attr.kind & 0xff {
+	case ValueKindError, ValueKindErrorEmbed:
+	default:
+		return src
+	}
+
+	e := (*Error)(unsafe.Pointer(attr.Value.srl.(*errorPtr)))
+	src = src[:len(src)-1]
+	src = AppendSerialized(src, Attr{
+		Value: Value{
+			num: ErrorFingerprint(e),
+		},
+		kind: ValueKindUint64 | ValuePredefinedNameFingerprint,
+	})
+	return append(src, byte(ValueKindGroupEnd))
+}
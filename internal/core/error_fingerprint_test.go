@@ -0,0 +1,103 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestErrorFingerprint(t *testing.T) {
+	core.InsertLocationsOff()
+
+	get := func(id int) error {
+		return core.WrapError(core.NewError("no funds").Int("account-id", id), "charge").Str("step", "invoice")
+	}
+
+	if core.ErrorFingerprint(get(1)) != core.ErrorFingerprint(get(2)) {
+		t.Error("errors differing in context values only must have the same fingerprint")
+	}
+	if core.ErrorFingerprint(get(1)) == core.ErrorFingerprint(core.NewError("no funds")) {
+		t.Error("errors with different stages must have different fingerprints")
+	}
+	if core.ErrorFingerprint(core.NewError("no funds")) == core.ErrorFingerprint(core.NewError("no fund")) {
+		t.Error("errors with different messages must have different fingerprints")
+	}
+	if core.ErrorFingerprint(fmt.Errorf("wrap: %w", get(1))) != core.ErrorFingerprint(get(2)) {
+		t.Error("foreign wraps must not change the fingerprint")
+	}
+	if core.ErrorFingerprint(io.EOF) != core.ErrorFingerprint(io.EOF) {
+		t.Error("foreign errors must have stable fingerprints")
+	}
+}
+
+func TestErrorFingerprintFormatted(t *testing.T) {
+	core.InsertLocationsOn()
+	defer core.InsertLocationsOff()
+
+	get := func(format string, a ...any) error {
+		return core.WrapErrorf(io.EOF, format, a...)
+	}
+
+	tests := []struct {
+		name  string
+		a, b  error
+		equal bool
+	}{
+		{"numbers", get("user %d", 1), get("user %d", 2), true},
+		{"identifiers", get("order %s", "ord-42"), get("order %s", "ord-7f3a"), true},
+		{"quoted", get("table %q", "users"), get("table %q", `say "hi"`), true},
+		{"words", get("user %s", "alice"), get("user %s", "bob"), false},
+		{"formats", get("user %d", 1), get("account %d", 1), false},
+		{
+			name:  "foreign texts",
+			a:     fmt.Errorf("dial tcp 10.0.0.1:5432: connection refused"),
+			b:     fmt.Errorf("dial tcp 10.0.0.2:5432: connection refused"),
+			equal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := core.ErrorFingerprint(tt.a) == core.ErrorFingerprint(tt.b); got != tt.equal {
+				t.Errorf("fingerprints of %q and %q equal = %v, want %v", tt.a, tt.b, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestErrorFingerprintLocation(t *testing.T) {
+	for _, file := range []string{
+		"/home/dev/src/blog/internal/core/error.go",
+		"/builds/ci/blog/internal/core/error.go",
+	} {
+		if got := core.FingerprintLocation(file); got != "core/error.go" {
+			t.Errorf("FingerprintLocation(%q) = %q, want core/error.go", file, got)
+		}
+	}
+}
+
+func TestOptionErrorFingerprints(t *testing.T) {
+	core.InsertLocationsOff()
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf), blog.OptionErrorFingerprints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := core.NewError("no funds").Int("account-id", 42)
+	logger.Error(context.Background(), "failed", blog.Err(e))
+
+	want := "@fingerprint: " + strconv.FormatUint(core.ErrorFingerprint(e), 16)
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in the output:\n%s", want, buf.String())
+	}
+	if !strings.Contains(buf.String(), "account-id: 42") {
+		t.Errorf("missing the error context in the output:\n%s", buf.String())
+	}
+}
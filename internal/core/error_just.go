@@ -37,8 +37,6 @@ func JustError(err error) *Error {
 	}
 	res.payload = AppendSerialized(res.payload, attr)
 
-	if insertLocations {
-		res.appendLocation(3)
-	}
+	res.appendLocation(3)
 	return res
 }
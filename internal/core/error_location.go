@@ -2,22 +2,153 @@ package core
 
 import (
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-var insertLocations bool
+var (
+	insertLocations atomic.Bool
+	locationRules   atomic.Pointer[locationRuleSet]
+	locationLock    sync.Mutex
+)
 
 // InsertLocationsOn enables location retrieval in [NewError]/[NewErrorf], [WrapError]/[WrapErrorf] and [JustError] calls.
 // It is disabled by default. And better not to be enabled in production environments.
 func InsertLocationsOn() {
-	insertLocations = true
+	insertLocations.Store(true)
 }
 
 // InsertLocationsOff disables location retrieval in [NewError]/[NewErrorf], [WrapError]/[WrapErrorf] and [JustError] calls.
+// Locations enabled for packages with [InsertLocationsOnFor] are still retrieved.
 func InsertLocationsOff() {
-	insertLocations = false
+	insertLocations.Store(false)
+}
+
+// InsertLocationsOnFor enables location retrieval for errors created in packages with given
+// import paths and in their subpackages. Safe to be called at runtime.
+func InsertLocationsOnFor(paths ...string) {
+	locationLock.Lock()
+	defer locationLock.Unlock()
+
+	var prefixes []string
+	if rules := locationRules.Load(); rules != nil {
+		prefixes = slices.Clone(rules.prefixes)
+	}
+	for _, path := range paths {
+		path = strings.TrimSuffix(path, "/")
+		if !slices.Contains(prefixes, path) {
+			prefixes = append(prefixes, path)
+		}
+	}
+
+	locationRules.Store(newLocationRuleSet(prefixes))
+}
+
+// InsertLocationsOffFor cancels [InsertLocationsOnFor] for given import paths.
+func InsertLocationsOffFor(paths ...string) {
+	locationLock.Lock()
+	defer locationLock.Unlock()
+
+	rules := locationRules.Load()
+	if rules == nil {
+		return
+	}
+	prefixes := slices.DeleteFunc(slices.Clone(rules.prefixes), func(prefix string) bool {
+		return slices.Contains(paths, prefix) || slices.Contains(paths, prefix+"/")
+	})
+
+	locationRules.Store(newLocationRuleSet(prefixes))
+}
+
+// locationRuleSet is immutable except its cache. Changes of rules replace the whole set.
+type locationRuleSet struct {
+	prefixes []string
+	// cache maps program counters to decisions made for them.
+	cache sync.Map
+}
+
+func newLocationRuleSet(prefixes []string) *locationRuleSet {
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	return &locationRuleSet{
+		prefixes: prefixes,
+	}
+}
+
+func (s *locationRuleSet) match(pc uintptr) bool {
+	if v, ok := s.cache.Load(pc); ok {
+		return v.(bool)
+	}
+
+	var res bool
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function != "" {
+		pkg := funcPackagePath(frame.Function)
+		for _, prefix := range s.prefixes {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				res = true
+				break
+			}
+		}
+	}
+
+	s.cache.Store(pc, res)
+	return res
+}
+
+// funcPackagePath cuts an import path of the package out of the fully qualified function name
+// like github.com/sirkon/blog/internal/core.(*Error).appendLocation. Dots of the last element
+// of the path are escaped there, like gopkg.in/yaml%2ev3.Unmarshal, they are unescaped back.
+func funcPackagePath(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return unescapePackagePath(name)
+	}
+
+	return unescapePackagePath(name[:slash+1+dot])
+}
+
+// unescapePackagePath undoes %xx escaping the linker applies to symbol names.
+func unescapePackagePath(path string) string {
+	if !strings.Contains(path, "%") {
+		return path
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+2 < len(path) {
+			if v, err := strconv.ParseUint(path[i+1:i+3], 16, 8); err == nil {
+				buf.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		buf.WriteByte(path[i])
+	}
+
+	return buf.String()
 }
 
 func (e *Error) appendLocation(skip int) {
+	if !insertLocations.Load() {
+		rules := locationRules.Load()
+		if rules == nil {
+			return
+		}
+
+		// Callers counts itself in unlike Caller.
+		var pcs [1]uintptr
+		if runtime.Callers(skip+1, pcs[:]) == 0 || !rules.match(pcs[0]) {
+			return
+		}
+	}
+
 	_, fn, line, ok := runtime.Caller(skip)
 	if !ok {
 		return
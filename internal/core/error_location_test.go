@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sirkon/blog/beer"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestInsertLocationsOnFor(t *testing.T) {
+	core.InsertLocationsOff()
+	plain := core.PayloadLen(beer.New("error"))
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  bool
+	}{
+		{
+			name:  "this package",
+			paths: []string{"github.com/sirkon/blog/internal/core_test"},
+			want:  true,
+		},
+		{
+			name:  "parent package",
+			paths: []string{"github.com/sirkon/blog/internal/"},
+			want:  true,
+		},
+		{
+			name:  "sibling package",
+			paths: []string{"github.com/sirkon/blog/internal/core"},
+			want:  false,
+		},
+		{
+			name:  "name prefix",
+			paths: []string{"github.com/sirkon/blog/internal/co"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core.InsertLocationsOnFor(tt.paths...)
+			defer core.InsertLocationsOffFor(tt.paths...)
+
+			got := core.PayloadLen(beer.New("error")) > plain
+			if got != tt.want {
+				t.Errorf("location captured = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if core.PayloadLen(beer.New("error")) != plain {
+		t.Error("location must not be captured after rules were cancelled")
+	}
+}
+
+func TestInsertLocationsOnForConcurrent(t *testing.T) {
+	const path = "github.com/sirkon/blog/internal/core_test"
+	defer core.InsertLocationsOffFor(path)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for range 1000 {
+				if i%2 == 0 {
+					core.InsertLocationsOnFor(path)
+				} else {
+					core.InsertLocationsOffFor(path)
+				}
+				_ = beer.Wrap(beer.New("error"), "wrap")
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestFuncPackagePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"github.com/sirkon/blog/internal/core.(*Error).appendLocation", "github.com/sirkon/blog/internal/core"},
+		{"main.main", "main"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+		{"gopkg.in/yaml%2ev3.(*decoder).unmarshal.func1", "gopkg.in/yaml.v3"},
+		{"example.com/broken%zz.F", "example.com/broken%zz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := core.FuncPackagePath(tt.name); got != tt.want {
+				t.Errorf("FuncPackagePath(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -26,9 +26,7 @@ func newError(msg string) *Error {
 		sufficient: true,
 	}
 
-	if insertLocations {
-		res.appendLocation(4)
-	}
+	res.appendLocation(4)
 
 	return res
 }
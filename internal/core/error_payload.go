@@ -0,0 +1,135 @@
+package core
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// splitPayloadKey reads a key of the node of given kind from the payload.
+//
+// Keys are either length prefixed strings or a zero byte followed by UVARINT index
+// of the predefined key. Error stage nodes may have empty keys, so the zero byte
+// is always an empty string for them: they never use predefined keys.
+func splitPayloadKey(kind ValueKind, payload []byte) (key []byte, rest []byte) {
+	if payload[0] != 0 || isStageNodeKind(kind) {
+		length, varintLength := binary.Uvarint(payload)
+		if length > 0 {
+			key = payload[varintLength : varintLength+int(length)]
+		}
+		return key, payload[varintLength+int(length):]
+	}
+
+	index, varintLength := binary.Uvarint(payload[1:])
+	payload = payload[1+varintLength:]
+	if index == 0 || index > uint64(len(PredefinedKeys)) {
+		return nil, payload
+	}
+	kkk := PredefinedKeys[index-1]
+	return unsafe.Slice(unsafe.StringData(kkk), len(kkk)), payload
+}
+
+func isStageNodeKind(kind ValueKind) bool {
+	switch kind {
+	case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+		ValueKindForeignErrorText, ValueKindRemoteNode:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitPayloadNode splits the head node of the payload. Returns its kind, key and
+// value bytes and the rest of the payload.
+//
+// Nested nodes of groups, errors and remote stages are not the part of the value,
+// they follow in the rest of payload as they were serialized. The value of a remote
+// stage node is its text and the length of the remote payload, see [splitRemoteNodeValue].
+func splitPayloadNode(payload []byte) (kind ValueKind, key []byte, value []byte, rest []byte) {
+	kind = ValueKind(payload[0])
+	payload = payload[1:]
+	switch kind {
+	case ValueKindJustContextNode, ValueKindJustContextInheritedNode,
+		ValueKindPhantomContextNode, ValueKindGroupEnd:
+		return kind, nil, nil, payload
+	}
+
+	key, payload = splitPayloadKey(kind, payload)
+	rest = payload
+
+	var size int
+	switch kind {
+	case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+		ValueKindForeignErrorText, ValueKindGroup, ValueKindError, ValueKindSecret:
+	case ValueKindLocationNode:
+		_, size = binary.Uvarint(payload)
+	case ValueKindRemoteNode:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength + int(length)
+		_, varintLength = binary.Uvarint(payload[size:])
+		size += varintLength
+		return kind, key, payload[:size], payload[size:]
+	case ValueKindBool, ValueKindInt8, ValueKindUint8:
+		size = 1
+	case ValueKindInt16, ValueKindUint16:
+		size = 2
+	case ValueKindInt32, ValueKindUint32, ValueKindFloat32:
+		size = 4
+	case ValueKindTime, ValueKindDuration,
+		ValueKindInt, ValueKindInt64,
+		ValueKindUint, ValueKindUint64,
+		ValueKindFloat64:
+		size = 8
+	case ValueKindString, ValueKindBytes, ValueKindErrorRaw, ValueKindErrorEmbed,
+		ValueKindSliceBool, ValueKindSliceInt8, ValueKindSliceUint8:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength + int(length)
+	case ValueKindSliceInt16, ValueKindSliceUint16:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength + 2*int(length)
+	case ValueKindSliceInt32, ValueKindSliceUint32, ValueKindSliceFloat32:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength + 4*int(length)
+	case ValueKindSliceInt, ValueKindSliceInt64,
+		ValueKindSliceUint, ValueKindSliceUint64,
+		ValueKindSliceFloat64:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength + 8*int(length)
+	case ValueKindSliceString:
+		length, varintLength := binary.Uvarint(payload)
+		size = varintLength
+		for range length {
+			l, vl := binary.Uvarint(payload[size:])
+			size += vl + int(l)
+		}
+	}
+
+	return kind, key, rest[:size], rest[size:]
+}
+
+// splitRemoteNodeValue splits the value of a remote stage node into the remote error text
+// and the length of the remote payload following the node.
+func splitRemoteNodeValue(value []byte) (text []byte, nested int) {
+	length, varintLength := binary.Uvarint(value)
+	text = value[varintLength : varintLength+int(length)]
+	n, _ := binary.Uvarint(value[varintLength+int(length):])
+	return text, int(n)
+}
+
+// openPayloadStages computes how many error stages and groups are left open at the end of the payload.
+func openPayloadStages(payload []byte) int {
+	var depth int
+	for len(payload) > 0 {
+		var kind ValueKind
+		kind, _, _, payload = splitPayloadNode(payload)
+		switch kind {
+		case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+			ValueKindJustContextNode, ValueKindJustContextInheritedNode,
+			ValueKindGroup, ValueKindError, ValueKindErrorEmbed, ValueKindRemoteNode:
+			depth++
+		case ValueKindGroupEnd:
+			depth--
+		}
+	}
+
+	return depth
+}
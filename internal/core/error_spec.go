@@ -2,7 +2,9 @@ package core
 
 import (
 	"errors"
+	"iter"
 	"reflect"
+	"slices"
 )
 
 // specNode adds a mark of given type to an error.
@@ -23,6 +25,7 @@ func Spec(err error, spec any) *Error {
 			spec: spec,
 			next: e.specs,
 		}
+		e.appendCode(spec)
 		return e
 	}
 	if e, ok := errors.AsType[*Error](err); ok {
@@ -34,6 +37,7 @@ func Spec(err error, spec any) *Error {
 		}
 		wrapAttr := ErrorNodePhantomContext()
 		res.payload = AppendSerialized(res.payload, wrapAttr)
+		res.appendCode(spec)
 		return res
 	}
 
@@ -47,12 +51,31 @@ func Spec(err error, spec any) *Error {
 	}
 	wrapAttr := ErrorNodeForeignErrorText(err.Error())
 	res.payload = AppendSerialized(res.payload, wrapAttr)
-	attr := ErrorNodePhantomContext()
+	if _, ok := spec.(*ErrorCode); !ok {
+		attr := ErrorNodePhantomContext()
+		res.payload = AppendSerialized(res.payload, attr)
+		return res
+	}
+
+	// There is no stage to put the code into, open a context one as JustError does.
+	// It does not change the text of the error, unlike the inherited one.
+	attr := ErrorNodeJustContext()
 	res.payload = AppendSerialized(res.payload, attr)
+	res.appendCode(spec)
 
 	return res
 }
 
+// appendCode puts the code into the error context for the spec being an *[ErrorCode].
+func (e *Error) appendCode(spec any) {
+	code, ok := spec.(*ErrorCode)
+	if !ok {
+		return
+	}
+
+	e.payload = AppendSerialized(e.payload, predefinedStr(ValuePredefinedNameCode, code.name))
+}
+
 // AsSpec checks if an error was given a spec of certain type and
 // returns the spec.
 func AsSpec[T any](err error) (T, bool) {
@@ -111,3 +134,32 @@ func IsSpec[T any](err error) bool {
 		return false
 	}
 }
+
+// allSpecs iterates over specs of the error chain, from the most recent to the oldest.
+// Specs shared between errors of the chain are only yielded once.
+func allSpecs(err error) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		var seen []*specNode
+		for {
+			e, ok := err.(*Error)
+			if !ok {
+				e, ok = errors.AsType[*Error](err)
+			}
+			if !ok {
+				return
+			}
+
+			for spec := e.specs; spec != nil && !slices.Contains(seen, spec); spec = spec.next {
+				seen = append(seen, spec)
+				if !yield(spec.spec) {
+					return
+				}
+			}
+
+			if e.wrap == nil {
+				return
+			}
+			err = e.wrap
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+const errorWireVersion = 1
+
+// WireSpec is implemented by specs that must survive [MarshalErrorBinary]. Specs of other
+// types stay in the process where they were put.
+//
+// The decoder for the spec is to be registered with [RegisterWireSpec] under the
+// same name in the receiving process.
+type WireSpec interface {
+	// WireSpecName returns a name the decoder of this spec is registered with.
+	WireSpecName() string
+	// AppendWireSpec appends encoded spec to dst.
+	AppendWireSpec(dst []byte) []byte
+}
+
+var wireSpecs struct {
+	lock     sync.RWMutex
+	decoders map[string]func(data []byte) (any, error)
+}
+
+// RegisterWireSpec registers a decoder of specs encoded by [WireSpec] implementations with the given name.
+// Specs whose names have no registered decoders are dropped by [UnmarshalErrorBinary].
+func RegisterWireSpec(name string, decode func(data []byte) (any, error)) {
+	wireSpecs.lock.Lock()
+	defer wireSpecs.lock.Unlock()
+
+	if wireSpecs.decoders == nil {
+		wireSpecs.decoders = map[string]func(data []byte) (any, error){}
+	}
+	wireSpecs.decoders[name] = decode
+}
+
+func lookupWireSpec(name []byte) func(data []byte) (any, error) {
+	wireSpecs.lock.RLock()
+	defer wireSpecs.lock.RUnlock()
+
+	return wireSpecs.decoders[string(name)]
+}
+
+// MarshalErrorBinary encodes an error into a binary form to ship it to another process,
+// where it is restored with [UnmarshalErrorBinary]. The origin names the sender, a service
+// typically.
+//
+// The following sequence is written:
+//
+//   - Version (1 byte)
+//   - UVARINT(len(origin)) | origin
+//   - UVARINT(len(text)) | text
+//   - UVARINT(len(payload)) | payload, where payload has all its stages closed.
+//   - UVARINT(specs_count) and then UVARINT(len(name)) | name | UVARINT(len(spec)) | spec for each [WireSpec].
+func MarshalErrorBinary(err error, origin string) ([]byte, error) {
+	if err == nil {
+		return nil, NewError("marshal nil error")
+	}
+
+	var payload []byte
+	e, ok := err.(*Error)
+	if !ok {
+		e, ok = errors.AsType[*Error](err)
+	}
+	if ok {
+		payload = e.payload
+	} else {
+		payload = AppendSerialized(make([]byte, 0, defaultPayloadSize), ErrorNodeForeignErrorText(err.Error()))
+	}
+	text := err.Error()
+	open := openPayloadStages(payload)
+
+	res := make([]byte, 0, 1+3*binary.MaxVarintLen32+len(origin)+len(text)+len(payload)+open+16)
+	res = append(res, errorWireVersion)
+	res = binary.AppendUvarint(res, uint64(len(origin)))
+	res = append(res, origin...)
+	res = binary.AppendUvarint(res, uint64(len(text)))
+	res = append(res, text...)
+	res = binary.AppendUvarint(res, uint64(len(payload)+open))
+	res = append(res, payload...)
+	for range open {
+		res = append(res, byte(ValueKindGroupEnd))
+	}
+
+	var specs []WireSpec
+	for spec := range allSpecs(err) {
+		if ws, ok := spec.(WireSpec); ok {
+			specs = append(specs, ws)
+		}
+	}
+	res = binary.AppendUvarint(res, uint64(len(specs)))
+	var buf []byte
+	for _, spec := range specs {
+		name := spec.WireSpecName()
+		res = binary.AppendUvarint(res, uint64(len(name)))
+		res = append(res, name...)
+		buf = spec.AppendWireSpec(buf[:0])
+		res = binary.AppendUvarint(res, uint64(len(buf)))
+		res = append(res, buf...)
+	}
+
+	return res, nil
+}
+
+// UnmarshalErrorBinary restores an error encoded with [MarshalErrorBinary].
+//
+// The remote error becomes a single stage of the returned *[Error], with the whole remote
+// context nested in it. The origin of the remote error is the key of this stage.
+func UnmarshalErrorBinary(data []byte) (*Error, error) {
+	if len(data) == 0 {
+		return nil, NewError("empty data")
+	}
+	if data[0] != errorWireVersion {
+		return nil, NewErrorf("wire version %d does not match the supported version %d", data[0], errorWireVersion)
+	}
+	data = data[1:]
+
+	origin, data, err := readWireString(data)
+	if err != nil {
+		return nil, WrapError(err, "read origin")
+	}
+	text, data, err := readWireString(data)
+	if err != nil {
+		return nil, WrapError(err, "read text")
+	}
+	payload, data, err := readWireString(data)
+	if err != nil {
+		return nil, WrapError(err, "read payload")
+	}
+	if err := validatePayload(payload); err != nil {
+		return nil, WrapError(err, "validate payload")
+	}
+
+	res := &Error{
+		payload:    make([]byte, 0, 1+len(origin)+len(text)+len(payload)+3*binary.MaxVarintLen32),
+		sufficient: true,
+	}
+	res.payload = append(res.payload, byte(ValueKindRemoteNode))
+	res.payload = binary.AppendUvarint(res.payload, uint64(len(origin)))
+	res.payload = append(res.payload, origin...)
+	res.payload = binary.AppendUvarint(res.payload, uint64(len(text)))
+	res.payload = append(res.payload, text...)
+	res.payload = binary.AppendUvarint(res.payload, uint64(len(payload)))
+	res.payload = append(res.payload, payload...)
+
+	count, data, err := readUvarint(data)
+	if err != nil {
+		return nil, WrapError(err, "read specs count")
+	}
+	specs := make([]any, 0, count)
+	for i := range count {
+		var name, spec []byte
+		name, data, err = readWireString(data)
+		if err != nil {
+			return nil, WrapError(err, "read spec name").Int("spec-index", i)
+		}
+		spec, data, err = readWireString(data)
+		if err != nil {
+			return nil, WrapError(err, "read spec").Int("spec-index", i).Bytes("spec-name", name)
+		}
+
+		decode := lookupWireSpec(name)
+		if decode == nil {
+			continue
+		}
+		v, err := decode(spec)
+		if err != nil {
+			return nil, WrapError(err, "decode spec").Bytes("spec-name", name)
+		}
+		specs = append(specs, v)
+	}
+	for i := len(specs) - 1; i >= 0; i-- {
+		res.specs = &specNode{
+			spec: specs[i],
+			next: res.specs,
+		}
+	}
+
+	if len(data) > 0 {
+		return nil, NewErrorf("%d bytes of unexpected data after the error", len(data))
+	}
+
+	return res, nil
+}
+
+func readWireString(src []byte) ([]byte, []byte, error) {
+	length, src, err := readUvarint(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if length > len(src) {
+		return nil, nil, NewErrorf("string length %d is out of data length %d", length, len(src))
+	}
+
+	return src[:length], src[length:], nil
+}
+
+// validatePayload makes sure the payload can be walked through safely.
+func validatePayload(payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewError("malformed payload")
+		}
+	}()
+
+	if openPayloadStages(payload) != 0 {
+		return NewError("payload has unclosed stages")
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+type wireTenant string
+
+func (t wireTenant) WireSpecName() string { return "tenant" }
+
+func (t wireTenant) AppendWireSpec(dst []byte) []byte { return append(dst, t...) }
+
+func init() {
+	core.RegisterWireSpec("tenant", func(data []byte) (any, error) {
+		return wireTenant(data), nil
+	})
+}
+
+func ExampleUnmarshalErrorBinary() {
+	var err error
+	err = core.WrapError(io.EOF, "read account").Int("account-id", 42)
+	err = fmt.Errorf("foreign wrap: %w", err)
+	err = core.WrapError(err, "charge")
+	err = core.Spec(err, wireTenant("acme"))
+	err = core.Spec(err, 12) // Not a WireSpec, will not be shipped.
+
+	data, merr := core.MarshalErrorBinary(err, "billing")
+	if merr != nil {
+		fmt.Println("marshal:", merr)
+		return
+	}
+
+	remote, uerr := core.UnmarshalErrorBinary(data)
+	if uerr != nil {
+		fmt.Println("unmarshal:", uerr)
+		return
+	}
+	tenant, _ := core.AsSpec[wireTenant](remote)
+	fmt.Println(remote.Error(), "|", tenant, core.IsSpec[int](remote))
+
+	err = core.WrapError(remote, "process invoice")
+	fmt.Println(err.Error())
+
+	// Output:
+	// charge: foreign wrap: read account: EOF | acme false
+	// process invoice: charge: foreign wrap: read account: EOF
+}
+
+func TestUnmarshalErrorBinaryLogging(t *testing.T) {
+	core.InsertLocationsOff()
+
+	data, err := core.MarshalErrorBinary(core.NewError("no funds").Int("account-id", 42), "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := core.UnmarshalErrorBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Error(context.Background(), "failed", blog.Err(core.WrapError(remote, "charge").Str("step", "invoice")))
+
+	for _, want := range []string{
+		"REMOTE: billing",
+		"NEW: no funds",
+		"account-id: 42",
+		"step: invoice",
+		"@text: charge: no funds",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%q is missing in the output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestUnmarshalErrorBinaryMalformed(t *testing.T) {
+	data, err := core.MarshalErrorBinary(io.EOF, "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range len(data) {
+		if _, err := core.UnmarshalErrorBinary(data[:i]); err == nil {
+			t.Errorf("error expected for the data truncated to %d bytes", i)
+		}
+	}
+}
@@ -49,8 +49,6 @@ func wrapError(err error, msg string) *Error {
 	}
 	res.payload = AppendSerialized(res.payload, attr)
 
-	if insertLocations {
-		res.appendLocation(4)
-	}
+	res.appendLocation(4)
 	return res
 }
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"time"
+)
+
+// FuncPackagePath exposes funcPackagePath to tests.
+var FuncPackagePath = funcPackagePath
+
+// Insert adds any attribute to the context of the error, including ones the API does not allow.
+func (e *Error) Insert(attr Attr) *Error {
+	return e.insert(attr)
+}
+
+// FingerprintLocation exposes fingerprintLocation to tests.
+var FingerprintLocation = fingerprintLocation
+
+// OptionLimitsClock replaces the clock of sampling and rate limits.
+func OptionLimitsClock(now func() time.Time) OptionApplier {
+	return optionLimitsClock(now)
+}
+
+type optionLimitsClock func() time.Time
+
+func (e optionLimitsClock) String() string {
+	return "limits clock"
+}
+
+func (e optionLimitsClock) apply(l *Logger) error {
+	l.limitsSetup().clock = func() int64 {
+		return e().UnixNano()
+	}
+	return nil
+}
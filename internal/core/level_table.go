@@ -0,0 +1,212 @@
+package core
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelTable decides logging levels of components of loggers made with [Logger.Named].
+// A level of the component is the one set for its name or for the longest of its prefixes,
+// so a level of "db" applies to "db.pool" unless "db.pool" has its own.
+//
+// Loggers set up with [OptionLevelTable] cache levels of their components, so a lookup is
+// a couple of atomic loads until the table is changed.
+//
+// The zero value logs all levels of all components. It is safe for concurrent use.
+type LevelTable struct {
+	lock  sync.Mutex
+	rules atomic.Pointer[levelTableRules]
+}
+
+// levelTableEmpty are rules of the zero table.
+var levelTableEmpty = &levelTableRules{
+	def: LoggingLevelTrace,
+}
+
+// levelTableRules are immutable, changes of the table replace them.
+type levelTableRules struct {
+	// gen identifies rules in caches of components.
+	gen        uint64
+	def        LoggingLevel
+	components map[string]LoggingLevel
+}
+
+// NewLevelTable creates a table with the given level for components having no levels set.
+func NewLevelTable(def LoggingLevel) *LevelTable {
+	var res LevelTable
+	res.rules.Store(&levelTableRules{
+		gen: 1,
+		def: def,
+	})
+	return &res
+}
+
+// SetDefault sets a level for components having no levels set.
+func (t *LevelTable) SetDefault(level LoggingLevel) {
+	t.update(func(rules *levelTableRules) {
+		rules.def = level
+	})
+}
+
+// Set sets a level of the component and its subcomponents.
+func (t *LevelTable) Set(component string, level LoggingLevel) {
+	t.update(func(rules *levelTableRules) {
+		rules.components[component] = level
+	})
+}
+
+// Unset removes a level of the component, it will be inherited from its prefixes then.
+func (t *LevelTable) Unset(component string) {
+	t.update(func(rules *levelTableRules) {
+		delete(rules.components, component)
+	})
+}
+
+// Level returns a level of the component.
+func (t *LevelTable) Level(component string) LoggingLevel {
+	return t.load().level(component)
+}
+
+// load returns current rules of the table.
+func (t *LevelTable) load() *levelTableRules {
+	if rules := t.rules.Load(); rules != nil {
+		return rules
+	}
+
+	return levelTableEmpty
+}
+
+func (t *LevelTable) update(change func(rules *levelTableRules)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	prev := t.load()
+	next := &levelTableRules{
+		gen:        prev.gen + 1,
+		def:        prev.def,
+		components: maps.Clone(prev.components),
+	}
+	if next.components == nil {
+		next.components = map[string]LoggingLevel{}
+	}
+	change(next)
+	t.rules.Store(next)
+}
+
+func (r *levelTableRules) level(component string) LoggingLevel {
+	for len(r.components) > 0 {
+		if level, ok := r.components[component]; ok {
+			return level
+		}
+
+		dot := strings.LastIndexByte(component, '.')
+		if dot < 0 {
+			break
+		}
+		component = component[:dot]
+	}
+
+	return r.def
+}
+
+// componentLevel returns a level of the component of a logger.
+func (t *LevelTable) componentLevel(c *loggerComponent) LoggingLevel {
+	rules := t.load()
+	if c == nil {
+		return rules.def
+	}
+
+	// The cache keeps generation of rules in upper bits and the level in the lowest byte.
+	cached := c.cache.Load()
+	if cached>>8 == rules.gen {
+		return LoggingLevel(cached)
+	}
+
+	level := rules.level(c.name)
+	c.cache.Store(rules.gen<<8 | uint64(level))
+	return level
+}
+
+type levelTableHTTPBody struct {
+	Default    string            `json:"default"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// ServeHTTP reports levels of the table with GET and changes them with PUT or POST. Both use JSON like
+//
+//	{"default": "INFO", "components": {"db.pool": "DEBUG"}}
+//
+// Changes are applied to given components only, empty levels unset them.
+func (t *LevelTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var body levelTableHTTPBody
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, levelHTTPMaxBody)).Decode(&body); err != nil {
+			http.Error(w, "decode request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := t.apply(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	rules := t.load()
+	body := levelTableHTTPBody{
+		Default: rules.def.String(),
+	}
+	if len(rules.components) > 0 {
+		body.Components = make(map[string]string, len(rules.components))
+		for component, level := range rules.components {
+			body.Components[component] = level.String()
+		}
+	}
+	data, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func (t *LevelTable) apply(body levelTableHTTPBody) error {
+	var def LoggingLevel
+	if body.Default != "" {
+		level, err := ParseLoggingLevel(body.Default)
+		if err != nil {
+			return err
+		}
+		def = level
+	}
+	levels := make(map[string]LoggingLevel, len(body.Components))
+	for component, name := range body.Components {
+		if name == "" {
+			continue
+		}
+		level, err := ParseLoggingLevel(name)
+		if err != nil {
+			return err
+		}
+		levels[component] = level
+	}
+
+	t.update(func(rules *levelTableRules) {
+		if def != loggingLevelInvalid {
+			rules.def = def
+		}
+		for component, name := range body.Components {
+			if name == "" {
+				delete(rules.components, component)
+				continue
+			}
+			rules.components[component] = levels[component]
+		}
+	})
+	return nil
+}
@@ -0,0 +1,114 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestLevelTable(t *testing.T) {
+	levels := blog.NewLevelTable(blog.LevelInfo)
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf), blog.OptionLevelTable(levels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := logger.Named("db")
+	pool := db.Named("pool").With(blog.Int("size", 10))
+	query := db.Named("query")
+
+	logDebug := func() {
+		logger.Debug(context.Background(), "root")
+		pool.Debug(context.Background(), "pool")
+		query.Debug(context.Background(), "query")
+	}
+
+	logDebug()
+	if buf.Len() > 0 {
+		t.Errorf("nothing must be logged at the default level:\n%s", buf.String())
+	}
+
+	levels.Set("db.pool", blog.LevelDebug)
+	logDebug()
+	if got := buf.String(); !strings.Contains(got, "DEBUG pool") || strings.Contains(got, "root") || strings.Contains(got, "query") {
+		t.Errorf("only db.pool must be logged:\n%s", got)
+	}
+	if !strings.Contains(buf.String(), `"@component": "db.pool"`) {
+		t.Errorf("missing component name:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	levels.Set("db", blog.LevelError)
+	levels.Unset("db.pool")
+	levels.SetDefault(blog.LevelTrace)
+	logDebug()
+	if got := buf.String(); !strings.Contains(got, "DEBUG root") || strings.Contains(got, "pool") || strings.Contains(got, "query") {
+		t.Errorf("db must inherit the level of db:\n%s", got)
+	}
+}
+
+func TestLevelTableZero(t *testing.T) {
+	var levels blog.LevelTable
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf), blog.OptionLevelTable(&levels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := logger.Named("db")
+
+	db.Trace(context.Background(), "trace")
+	if !strings.Contains(buf.String(), "TRACE trace") {
+		t.Errorf("the zero table must log all levels:\n%s", buf.String())
+	}
+	if got := levels.Level("db"); got != blog.LevelTrace {
+		t.Errorf("expected level %s, got %s", blog.LevelTrace, got)
+	}
+
+	buf.Reset()
+	levels.Set("db", blog.LevelInfo)
+	db.Debug(context.Background(), "debug")
+	if buf.Len() > 0 {
+		t.Errorf("nothing must be logged below the level set:\n%s", buf.String())
+	}
+}
+
+func TestLevelTableServeHTTP(t *testing.T) {
+	levels := core.NewLevelTable(core.LoggingLevelInfo)
+
+	rec := httptest.NewRecorder()
+	levels.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPut,
+		"/levels",
+		strings.NewReader(`{"components": {"db.pool": "debug"}}`),
+	))
+	if want := `{"default":"INFO","components":{"db.pool":"DEBUG"}}`; rec.Body.String() != want {
+		t.Errorf("expected %s, got %s", want, rec.Body.String())
+	}
+	if levels.Level("db.pool.conn") != core.LoggingLevelDebug || levels.Level("db") != core.LoggingLevelInfo {
+		t.Error("unexpected levels after the change")
+	}
+
+	rec = httptest.NewRecorder()
+	levels.ServeHTTP(rec, httptest.NewRequest(
+		http.MethodPost,
+		"/levels",
+		strings.NewReader(`{"default": "warn", "components": {"db.pool": ""}}`),
+	))
+	if want := `{"default":"WARN"}`; rec.Body.String() != want {
+		t.Errorf("expected %s, got %s", want, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	levels.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"default": "loud"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request for unknown level, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+)
+
+// LevelVar is a logging level that can be changed at runtime. Loggers set up with [OptionLevelVar]
+// and their descendants made with [Logger.With] consult it on each call.
+//
+// The zero value logs all levels. It is safe for concurrent use.
+type LevelVar struct {
+	v atomic.Uint32
+}
+
+// NewLevelVar creates a variable set to the given level.
+func NewLevelVar(level LoggingLevel) *LevelVar {
+	var res LevelVar
+	res.Set(level)
+	return &res
+}
+
+// Level returns the current level.
+func (v *LevelVar) Level() LoggingLevel {
+	return LoggingLevel(v.v.Load())
+}
+
+// Set changes the level.
+func (v *LevelVar) Set(level LoggingLevel) {
+	v.v.Store(uint32(level))
+}
+
+func (v *LevelVar) String() string {
+	return "LevelVar(" + v.Level().String() + ")"
+}
+
+// levelHTTPMaxBody limits sizes of requests changing levels.
+const levelHTTPMaxBody = 64 << 10
+
+type levelVarHTTPBody struct {
+	Level string `json:"level"`
+}
+
+// ServeHTTP reports the level with GET and changes it with PUT or POST. Both use JSON like
+//
+//	{"level": "DEBUG"}
+func (v *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var body levelVarHTTPBody
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, levelHTTPMaxBody)).Decode(&body); err != nil {
+			http.Error(w, "decode request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := ParseLoggingLevel(body.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		v.Set(level)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, _ := json.Marshal(levelVarHTTPBody{Level: v.Level().String()})
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// ToggleLevelOnSignal switches the variable between its current level and the given one
+// on each of the given signals, SIGUSR1 for instance. Call the returned function to stop.
+//
+// It panics if no signals are given: that would subscribe to all of them, so interrupts
+// would toggle the level instead of stopping the process.
+func ToggleLevelOnSignal(v *LevelVar, level LoggingLevel, sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		panic("no signals to toggle the logging level on")
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, sig...)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				current := v.Level()
+				v.Set(level)
+				level = current
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// ParseLoggingLevel parses level names as they are returned by [LoggingLevel.String].
+// Names are case-insensitive, WARNING is accepted as well.
+func ParseLoggingLevel(s string) (LoggingLevel, error) {
+	switch strings.ToUpper(s) {
+	case "TRACE":
+		return LoggingLevelTrace, nil
+	case "DEBUG":
+		return LoggingLevelDebug, nil
+	case "INFO":
+		return LoggingLevelInfo, nil
+	case "WARN", "WARNING":
+		return LoggingLevelWarning, nil
+	case "ERROR":
+		return LoggingLevelError, nil
+	case "PANIC":
+		return LoggingLevelPanic, nil
+	case "FATAL":
+		return LoggingLevelFatal, nil
+	default:
+		return loggingLevelInvalid, fmt.Errorf("unknown logging level %q", s)
+	}
+}
@@ -0,0 +1,106 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestLevelVar(t *testing.T) {
+	levels := blog.NewLevelVar(blog.LevelInfo)
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf), blog.OptionLevelVar(levels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived := logger.With(blog.Str("component", "storage"))
+
+	derived.Debug(context.Background(), "hidden")
+	levels.Set(blog.LevelDebug)
+	derived.Debug(context.Background(), "shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("derived loggers must consult the level var:\n%s", buf.String())
+	}
+}
+
+func TestLevelVarServeHTTP(t *testing.T) {
+	levels := core.NewLevelVar(core.LoggingLevelInfo)
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		body   string
+		code   int
+		want   string
+		level  core.LoggingLevel
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			code:   http.StatusOK,
+			want:   `{"level":"INFO"}`,
+			level:  core.LoggingLevelInfo,
+		},
+		{
+			name:   "put",
+			method: http.MethodPut,
+			body:   `{"level":"debug"}`,
+			code:   http.StatusOK,
+			want:   `{"level":"DEBUG"}`,
+			level:  core.LoggingLevelDebug,
+		},
+		{
+			name:   "unknown-level",
+			method: http.MethodPost,
+			body:   `{"level":"verbose"}`,
+			code:   http.StatusBadRequest,
+			level:  core.LoggingLevelDebug,
+		},
+		{
+			name:   "too-large",
+			method: http.MethodPut,
+			body:   `{"level":"info","padding":"` + strings.Repeat("x", 1<<20) + `"}`,
+			code:   http.StatusBadRequest,
+			level:  core.LoggingLevelDebug,
+		},
+		{
+			name:   "method-not-allowed",
+			method: http.MethodDelete,
+			code:   http.StatusMethodNotAllowed,
+			level:  core.LoggingLevelDebug,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			levels.ServeHTTP(rec, httptest.NewRequest(tt.method, "/level", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, got %d", tt.code, rec.Code)
+			}
+			if tt.want != "" && rec.Body.String() != tt.want {
+				t.Errorf("expected body %s, got %s", tt.want, rec.Body.String())
+			}
+			if levels.Level() != tt.level {
+				t.Errorf("expected level %s, got %s", tt.level, levels.Level())
+			}
+		})
+	}
+}
+
+func TestToggleLevelOnSignalNoSignals(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("empty list of signals must be rejected")
+		}
+	}()
+
+	stop := core.ToggleLevelOnSignal(core.NewLevelVar(core.LoggingLevelInfo), core.LoggingLevelDebug)
+	stop()
+}
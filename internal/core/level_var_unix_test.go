@@ -0,0 +1,31 @@
+//go:build unix
+
+package core_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestToggleLevelOnSignal(t *testing.T) {
+	levels := core.NewLevelVar(core.LoggingLevelInfo)
+	stop := core.ToggleLevelOnSignal(levels, core.LoggingLevelDebug, syscall.SIGUSR1)
+	defer stop()
+
+	for _, want := range []core.LoggingLevel{core.LoggingLevelDebug, core.LoggingLevelInfo, core.LoggingLevelDebug} {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for levels.Level() != want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if levels.Level() != want {
+			t.Fatalf("expected level %s, got %s", want, levels.Level())
+		}
+	}
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/binary"
 	"fmt"
@@ -10,7 +9,10 @@ import (
 	"io"
 	"math/bits"
 	"os"
+	"reflect"
 	"runtime"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,15 +30,25 @@ const (
 )
 
 // Logger records and serialize information about each call to its
-// Log, Debug, Info, Warn, and Error methods.
+// Log, Debug, Info, Warn, Error and Fatal methods.
 type Logger struct {
 	w          WriteSyncer
 	bufs       *sync.Pool
 	inProgress *uint64
 
-	logFrom       LoggingLevel
-	prefixPayload []byte
-	logLocations  bool
+	logFrom           LoggingLevel
+	levelVar          *LevelVar
+	levelTable        *LevelTable
+	component         *loggerComponent
+	prefixPayload     []byte
+	logLocations      bool
+	errorFingerprints bool
+	limits            *logLimits
+	fatalHook         func()
+	fatalStack        bool
+	stackAll          bool
+	hooks             []Hook
+	redact            *redactRules
 }
 
 // NewLogger creates a new logger writing into the given WriteSyncer.
@@ -62,27 +74,60 @@ type WriteSyncer interface {
 
 // Trace logs at [LoggingLevelTrace].
 func (l *Logger) Trace(ctx context.Context, msg string, attrs ...Attr) {
-	l.logLevel(ctx, LoggingLevelTrace, msg, attrs...)
+	l.logLevel(ctx, 0, LoggingLevelTrace, msg, attrs...)
 }
 
 // Debug logs at [LoggingLevelDebug].
 func (l *Logger) Debug(ctx context.Context, msg string, attrs ...Attr) {
-	l.logLevel(ctx, LoggingLevelDebug, msg, attrs...)
+	l.logLevel(ctx, 0, LoggingLevelDebug, msg, attrs...)
 }
 
 // Info logs at [LoggingLevelInfo].
 func (l *Logger) Info(ctx context.Context, msg string, attrs ...Attr) {
-	l.logLevel(ctx, LoggingLevelInfo, msg, attrs...)
+	l.logLevel(ctx, 0, LoggingLevelInfo, msg, attrs...)
 }
 
 // Warn logs at [LoggingLevelWarning].
 func (l *Logger) Warn(ctx context.Context, msg string, attrs ...Attr) {
-	l.logLevel(ctx, LoggingLevelWarning, msg, attrs...)
+	l.logLevel(ctx, 0, LoggingLevelWarning, msg, attrs...)
 }
 
 // Error logs at [LoggingLevelError].
 func (l *Logger) Error(ctx context.Context, msg string, attrs ...Attr) {
-	l.logLevel(ctx, LoggingLevelError, msg, attrs...)
+	l.logLevel(ctx, 0, LoggingLevelError, msg, attrs...)
+}
+
+// Fatal logs at [LoggingLevelFatal], syncs the writer if it has Sync() error method and calls
+// the exit hook, which is os.Exit(1) by default, see [OptionFatalHook]. The stack is logged
+// with the @stack key for the logger set up with [OptionFatalStack].
+func (l *Logger) Fatal(ctx context.Context, msg string, attrs ...Attr) {
+	if l.fatalStack {
+		attrs = append(attrs[:len(attrs):len(attrs)], stacktraceAttr(l.stacktrace()))
+	}
+	l.logLevel(ctx, 0, LoggingLevelFatal, msg, attrs...)
+
+	if err := l.Sync(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "failed to sync logged data:", err)
+	}
+	if l.fatalHook != nil {
+		l.fatalHook()
+		return
+	}
+	os.Exit(1)
+}
+
+// Sync logs the number of records dropped with [OptionSampling] and [OptionRateLimit]
+// not reported yet and syncs the writer if it has Sync() error method.
+func (l *Logger) Sync() error {
+	if l.limits != nil {
+		l.limits.report(l)
+	}
+
+	if s, ok := l.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
 }
 
 // With returns a Logger that includes the given attributes
@@ -91,11 +136,40 @@ func (l *Logger) With(ctx ...Attr) *Logger {
 	c := *l
 	c.prefixPayload = bytes.Clone(l.prefixPayload)
 	for _, attr := range ctx {
-		c.prefixPayload = AppendSerialized(c.prefixPayload, attr)
+		if l.redact != nil {
+			c.prefixPayload = l.redact.appendSerialized(c.prefixPayload, attr)
+		} else {
+			c.prefixPayload = AppendSerialized(c.prefixPayload, attr)
+		}
+		if l.errorFingerprints {
+			c.prefixPayload = appendErrorFingerprint(c.prefixPayload, attr)
+		}
+	}
+	return &c
+}
+
+// Named returns a Logger of the component with the given name. Names of nested components
+// are joined with dots, like db.pool. The name is logged with the @component key and
+// decides the level of the logger set up with [OptionLevelTable].
+func (l *Logger) Named(name string) *Logger {
+	c := *l
+	if l.component != nil {
+		name = l.component.name + "." + name
+	}
+	c.component = &loggerComponent{
+		name:    name,
+		payload: AppendSerialized(nil, predefinedStr(ValuePredefinedNameComponent, name)),
 	}
 	return &c
 }
 
+// loggerComponent is shared by a named logger and its descendants made with [Logger.With].
+type loggerComponent struct {
+	name    string
+	payload []byte
+	cache   atomic.Uint64
+}
+
 // LogPanic logs given stack trace at the Panic logging level.
 // Is to be used withing panic recovery routines, something like:
 //
@@ -108,25 +182,41 @@ func (l *Logger) With(ctx ...Attr) *Logger {
 //	    blog.LogPanic(ctx, logger, debug.Stack(), info)
 //	}()
 //
-// Panic text payload will be stored in gzipped form as a message.
+// The stack trace is parsed and logged as a group with the @stack key, with goroutines,
+// their frames and creators.
+//
+// Use [Go], [LogRecovered] or [NewRecoverHandler] to have this done for you.
 func LogPanic(ctx context.Context, log *Logger, stacktrace []byte, info Attr) {
-	var gzipped bytes.Buffer
-	if err := compressStacktrace(&gzipped, stacktrace); err != nil {
-		// We may only fail due to lack of memory. Our last hope is to write the stacktrace
-		// into the stderr.
-		_, _ = os.Stderr.Write(stacktrace)
-		return
+	log.logPanic(ctx, 0, stacktrace, info)
+}
+
+// logPanic is [LogPanic] with the location skip frames above the caller, see [Logger.logLevel].
+func (l *Logger) logPanic(ctx context.Context, skip int, stacktrace []byte, info Attr) {
+	l.logLevel(ctx, skip+1, LoggingLevelPanic, "panic", info, stacktraceAttr(stacktrace))
+}
+
+// stacktrace returns a stack trace of the current goroutine or of all goroutines
+// for the logger set up with [OptionStackAllGoroutines].
+func (l *Logger) stacktrace() []byte {
+	if !l.stackAll {
+		return debug.Stack()
 	}
 
-	log.logLevel(
-		ctx, LoggingLevelPanic,
-		unsafe.String(unsafe.SliceData(gzipped.Bytes()), gzipped.Len()),
-		info,
-	)
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
 }
 
 // LogPanicInfo extract panic "recovered" core in as meaningful form as possible.
 // Should be used within recovery procedures, see at [LogPanic] for usage example.
+//
+// Structs and maps with string keys become groups. Long slices and maps are cut, with the number
+// of items left out put with the @truncated key. See also [Go] and [LogRecovered].
 func LogPanicInfo(v any) Attr {
 	var attr Attr
 	key := "recovered"
@@ -192,7 +282,7 @@ func LogPanicInfo(v any) Attr {
 	case []string:
 		attr = Strs(key, v)
 	default:
-		attr = Str(key, fmt.Sprintf("%#v", v))
+		attr = reflectAttr(key, reflect.ValueOf(v), 0)
 	}
 	return attr
 }
@@ -211,6 +301,7 @@ func LogPanicInfo(v any) Attr {
 //   - ErrorStageLocation, either just 0 or UVARINT(len(file_name)) | file_name | UVARINT(LINE)
 //   - Some custom payload provided by [Logger.appendCustom] method.
 //   - Message UVARINT(len(message)) | message
+//   - Component name set with [Logger.Named].
 //   - Payload built with [Logger.With].
 //   - Serialized(attrs)
 //
@@ -219,15 +310,54 @@ func LogPanicInfo(v any) Attr {
 //
 // Beware that events may not be monotone in order: there's a possibility another goroutine starts 10s
 // after yet manages to pass through serialization earlier.
+//
+// The call site of the record is the caller of the logging method, skip frames further
+// for wrappers of logging methods. Frames of the runtime are passed by, so records made
+// by deferred functions during panics get the location of the panic.
 func (l *Logger) logLevel(
 	ctx context.Context,
+	skip int,
 	level LoggingLevel,
 	msg string,
 	attrs ...Attr,
 ) {
-	if level < l.logFrom {
+	if l.levelTable != nil {
+		if level < l.levelTable.componentLevel(l.component) {
+			return
+		}
+	} else if l.levelVar != nil {
+		if level < l.levelVar.Level() {
+			return
+		}
+	} else if level < l.logFrom {
 		return
 	}
+	if l.limits != nil {
+		var pc uintptr
+		if l.limits.sampling != nil {
+			// Callers counts itself in, the call site is right above the logging method.
+			var pcs [1]uintptr
+			if runtime.Callers(skip+3, pcs[:]) > 0 {
+				pc = pcs[0]
+			}
+		}
+
+		now := l.limits.clock()
+		allowed := l.limits.allow(level, msg, pc, now)
+		if dropped := l.limits.takeDropped(now); dropped > 0 {
+			l.logDropped(ctx, dropped)
+		}
+		if !allowed {
+			l.limits.scheduleReport(l)
+			return
+		}
+	}
+	if len(l.hooks) > 0 {
+		var keep bool
+		if attrs, keep = l.runHooks(ctx, skip, level, msg, attrs); !keep {
+			return
+		}
+	}
 
 	atomic.AddUint64(l.inProgress, 1)
 
@@ -260,11 +390,11 @@ func (l *Logger) logLevel(
 	if !l.logLocations {
 		record = append(record, 0)
 	} else {
-		_, fn, line, ok := runtime.Caller(2)
+		frame, ok := callSite(skip)
 		if ok {
-			record = binary.AppendUvarint(record, uint64(len(fn)))
-			record = append(record, fn...)
-			record = binary.AppendUvarint(record, uint64(line))
+			record = binary.AppendUvarint(record, uint64(len(frame.File)))
+			record = append(record, frame.File...)
+			record = binary.AppendUvarint(record, uint64(frame.Line))
 		} else {
 			// Failed to get caller info.
 			record = append(record, 0)
@@ -278,12 +408,24 @@ func (l *Logger) logLevel(
 	record = binary.AppendUvarint(record, uint64(len(msg)))
 	record = append(record, msg...)
 
+	// Component of the logger.
+	if l.component != nil {
+		record = append(record, l.component.payload...)
+	}
+
 	// With payload.
 	record = append(record, l.prefixPayload...)
 
 	// Serialize our attrs.
 	for _, attr := range attrs {
-		record = AppendSerialized(record, attr)
+		if l.redact != nil {
+			record = l.redact.appendSerialized(record, attr)
+		} else {
+			record = AppendSerialized(record, attr)
+		}
+		if l.errorFingerprints {
+			record = appendErrorFingerprint(record, attr)
+		}
 	}
 
 	// Get CRC32, adjust the placement, put header and form a data to write.
@@ -341,6 +483,8 @@ const (
 	// LoggingLevelError represents Error logging level.
 	LoggingLevelError LoggingLevel = 50
 	LoggingLevelPanic LoggingLevel = 60
+	// LoggingLevelFatal represents Fatal logging level.
+	LoggingLevelFatal LoggingLevel = 70
 )
 
 func (l LoggingLevel) String() string {
@@ -357,27 +501,25 @@ func (l LoggingLevel) String() string {
 		return "ERROR"
 	case LoggingLevelPanic:
 		return "PANIC"
+	case LoggingLevelFatal:
+		return "FATAL"
 	default:
 		return fmt.Sprintf("logging-level-unknown[%d]", uint8(l))
 	}
 }
 
-// compressStacktrace do not apply "proper" error processing. An error itself may only
-// be a consequence of [bytes.Buffer] errors and these may only happen due to a RAM shortage.
-// We don't have a luxury to append a right context in these conditions.
-func compressStacktrace(dst *bytes.Buffer, stacktrace []byte) (err error) {
-	w := gzip.NewWriter(dst)
-	defer func() {
-		if err != nil {
-			_ = w.Close()
-			return
+// callSite returns the frame of the call site of a logging method, see [Logger.logLevel].
+func callSite(skip int) (runtime.Frame, bool) {
+	// Skip Callers, callSite, logLevel and the logging method.
+	var pcs [16]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+4, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame, frame.PC != 0
+		}
+		if !more {
+			return runtime.Frame{}, false
 		}
-		err = w.Close()
-	}()
-
-	if _, err := w.Write(stacktrace); err != nil {
-		return err
 	}
-
-	return nil
 }
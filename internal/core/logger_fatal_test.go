@@ -0,0 +1,68 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+type syncRecorder struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (s *syncRecorder) Sync() error {
+	s.synced = true
+	return nil
+}
+
+func TestLoggerFatal(t *testing.T) {
+	var out syncRecorder
+	var exited bool
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&out),
+		blog.OptionFatalHook(func() {
+			if !out.synced {
+				t.Error("the writer must be synced before the exit hook")
+			}
+			exited = true
+		}),
+		blog.OptionFatalStack(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Fatal(context.Background(), "cannot start", blog.Str("config", "app.yaml"))
+
+	if !exited {
+		t.Error("the exit hook must be called")
+	}
+	for _, want := range []string{
+		"FATAL cannot start",
+		`"config": "app.yaml"`,
+		".... goroutine ",
+		"TestLoggerFatal",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in the output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "@stack") {
+		t.Errorf("the stack must be rendered apart from the context:\n%s", out.String())
+	}
+}
+
+func TestParseLoggingLevelFatal(t *testing.T) {
+	level, err := core.ParseLoggingLevel("fatal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != core.LoggingLevelFatal || level.String() != "FATAL" {
+		t.Errorf("unexpected level %s", level)
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"runtime"
+)
+
+// Hook processes records before they are written. Hooks are set up with [OptionHooks]
+// and are called in order for records passed level checks and limits.
+type Hook interface {
+	// Process returns attrs to be added to the record and false if the record is to be dropped.
+	// Attrs of the record must not be retained after the call.
+	Process(ctx context.Context, r HookRecord) (add []Attr, keep bool)
+}
+
+// HookRecord is a record being processed by a [Hook].
+type HookRecord struct {
+	Level   LoggingLevel
+	Message string
+	// Attrs of the call, including ones added by previous hooks. Attrs set with [Logger.With]
+	// are already serialized and not here.
+	Attrs []Attr
+	// PC is a program counter of the logging call, use [runtime.CallersFrames] to get its location.
+	PC uintptr
+}
+
+// HookFunc is a function implementing [Hook].
+type HookFunc func(ctx context.Context, r HookRecord) (add []Attr, keep bool)
+
+// Process to implement [Hook].
+func (f HookFunc) Process(ctx context.Context, r HookRecord) (add []Attr, keep bool) {
+	return f(ctx, r)
+}
+
+// runHooks passes the record through hooks.
+//
+// Hooks get copies of the message and attrs, since as far as the compiler knows hooks retain
+// what they are given, and passing originals would move arguments of every logging call to
+// the heap, even for loggers without hooks.
+func (l *Logger) runHooks(ctx context.Context, skip int, level LoggingLevel, msg string, attrs []Attr) ([]Attr, bool) {
+	// Skip Callers, runHooks and logLevel along with the method that called it.
+	var pcs [1]uintptr
+	runtime.Callers(skip+4, pcs[:])
+
+	r := HookRecord{
+		Level:   level,
+		Message: string([]byte(msg)),
+		Attrs:   append(make([]Attr, 0, len(attrs)), attrs...),
+		PC:      pcs[0],
+	}
+	for _, hook := range l.hooks {
+		add, keep := hook.Process(ctx, r)
+		if !keep {
+			return nil, false
+		}
+		if len(add) > 0 {
+			r.Attrs = append(r.Attrs, add...)
+		}
+	}
+
+	return r.Attrs, true
+}
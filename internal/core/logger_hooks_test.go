@@ -0,0 +1,53 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+)
+
+func TestOptionHooks(t *testing.T) {
+	errorsPerLocation := map[string]int{}
+	countErrors := blog.HookFunc(func(ctx context.Context, r blog.HookRecord) ([]blog.Attr, bool) {
+		if r.Level >= blog.LevelError {
+			frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+			errorsPerLocation[frame.Function]++
+		}
+		return nil, true
+	})
+	dropNoise := blog.HookFunc(func(ctx context.Context, r blog.HookRecord) ([]blog.Attr, bool) {
+		return nil, r.Message != "noise"
+	})
+	addVersion := blog.HookFunc(func(ctx context.Context, r blog.HookRecord) ([]blog.Attr, bool) {
+		return []blog.Attr{blog.Str("version", "1.2.3"), blog.Int("attrs", len(r.Attrs))}, true
+	})
+
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionHooks(countErrors, dropNoise),
+		blog.OptionHooks(addVersion),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info(context.Background(), "noise")
+	logger.Info(context.Background(), "started", blog.Int("port", 8080))
+	logger.Error(context.Background(), "failed")
+
+	out := buf.String()
+	if strings.Contains(out, "noise") {
+		t.Errorf("the record must be dropped:\n%s", out)
+	}
+	if !strings.Contains(out, `"port": 8080, "version": "1.2.3", "attrs": 1`) {
+		t.Errorf("attrs must be added to the record:\n%s", out)
+	}
+	if got := errorsPerLocation["github.com/sirkon/blog/internal/core_test.TestOptionHooks"]; got != 1 {
+		t.Errorf("expected an error counted for the test function, got %v", errorsPerLocation)
+	}
+}
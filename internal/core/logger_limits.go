@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// samplingSlots is the number of counters messages are spread over. Different messages
+	// may share a counter, this only makes their sampling a bit more aggressive.
+	samplingSlots = 1024
+
+	defaultDroppedReportInterval = 10 * time.Second
+)
+
+// logLimits drops records with sampling and rate limits. It is shared between a logger and
+// its descendants made with [Logger.With].
+//
+// Everything here is lock and allocation free, records are checked with a few atomic operations.
+type logLimits struct {
+	sampling *logSampling
+	// rates are indexed with level/10.
+	rates [int(LoggingLevelPanic)/10 + 1]*logRate
+
+	reportInterval int64
+	nextReport     atomic.Int64
+	dropped        atomic.Uint64
+	// reporting is set while a timer reporting dropped records is pending.
+	reporting atomic.Bool
+
+	// clock returns the current time in nanoseconds, it is replaced in tests.
+	clock func() int64
+}
+
+// logSampling logs first records with the same level, message and call site in each tick and then
+// every thereafter-th record.
+type logSampling struct {
+	tick       int64
+	first      uint64
+	thereafter uint64
+	slots      [samplingSlots]samplingSlot
+}
+
+type samplingSlot struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// logRate is a token bucket implemented with GCRA, so a single atomic is enough to keep its state.
+type logRate struct {
+	interval  int64
+	tolerance int64
+	// tat is a theoretical arrival time of the next record.
+	tat atomic.Int64
+}
+
+func (l *Logger) limitsSetup() *logLimits {
+	if l.limits == nil {
+		l.limits = &logLimits{
+			reportInterval: int64(defaultDroppedReportInterval),
+			clock:          nowNano,
+		}
+	}
+
+	return l.limits
+}
+
+func nowNano() int64 {
+	return time.Now().UnixNano()
+}
+
+// allow checks if the record is to be logged and counts it as dropped otherwise.
+// The pc is the call site of the record, it is only needed for sampling.
+func (ll *logLimits) allow(level LoggingLevel, msg string, pc uintptr, now int64) bool {
+	if level >= LoggingLevelPanic {
+		return true
+	}
+
+	if rate := ll.rates[level/10]; rate != nil && !rate.allow(now) {
+		ll.dropped.Add(1)
+		return false
+	}
+	if ll.sampling != nil && !ll.sampling.allow(level, msg, pc, now) {
+		ll.dropped.Add(1)
+		return false
+	}
+
+	return true
+}
+
+// scheduleReport makes sure dropped records are reported after the interval even if
+// no more records come, see [Logger.logDropped].
+func (ll *logLimits) scheduleReport(l *Logger) {
+	if !ll.reporting.CompareAndSwap(false, true) {
+		return
+	}
+
+	time.AfterFunc(time.Duration(ll.reportInterval), func() {
+		ll.reporting.Store(false)
+		ll.report(l)
+	})
+}
+
+// report logs dropped records not reported yet right away.
+func (ll *logLimits) report(l *Logger) {
+	if dropped := ll.dropped.Swap(0); dropped > 0 {
+		ll.nextReport.Store(ll.clock() + ll.reportInterval)
+		l.logDropped(context.Background(), dropped)
+	}
+}
+
+// takeDropped returns the number of dropped records when it is time to report them.
+func (ll *logLimits) takeDropped(now int64) uint64 {
+	next := ll.nextReport.Load()
+	if now < next || !ll.nextReport.CompareAndSwap(next, now+ll.reportInterval) {
+		return 0
+	}
+
+	return ll.dropped.Swap(0)
+}
+
+func (s *logSampling) allow(level LoggingLevel, msg string, pc uintptr, now int64) bool {
+	hash := fingerprintAppendByte(fingerprintOffset, byte(level))
+	for i := range len(msg) {
+		hash = fingerprintAppendByte(hash, msg[i])
+	}
+	for i := range 8 {
+		hash = fingerprintAppendByte(hash, byte(pc>>(8*i)))
+	}
+	slot := &s.slots[hash%samplingSlots]
+
+	if resetAt := slot.resetAt.Load(); now >= resetAt {
+		if slot.resetAt.CompareAndSwap(resetAt, now+s.tick) {
+			slot.count.Store(1)
+			return true
+		}
+	}
+
+	n := slot.count.Add(1)
+	if n <= s.first {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+func (r *logRate) allow(now int64) bool {
+	for {
+		tat := r.tat.Load()
+		next := max(tat, now) + r.interval
+		if next-now > r.tolerance {
+			return false
+		}
+		if r.tat.CompareAndSwap(tat, next) {
+			return true
+		}
+	}
+}
+
+// logDropped writes a summary of dropped records. It bypasses limits, [Logger.With] context
+// and locations of the logger.
+func (l *Logger) logDropped(ctx context.Context, dropped uint64) {
+	c := Logger{
+		w:          l.w,
+		bufs:       l.bufs,
+		inProgress: l.inProgress,
+	}
+	c.logLevel(ctx, 0, LoggingLevelWarning, "log records dropped", Uint64("dropped", dropped))
+}
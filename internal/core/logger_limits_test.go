@@ -0,0 +1,193 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestOptionSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionSampling(time.Hour, 3, 10),
+		blog.OptionDroppedReportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 100 {
+		logger.Info(context.Background(), "hot")
+		logger.Warn(context.Background(), "hot")
+	}
+	logger.Info(context.Background(), "cold")
+
+	// 3 first and then every 10th of 97 rest.
+	if got := countRecords(buf.String(), "INFO", "hot"); got != 12 {
+		t.Errorf("expected 12 info records, got %d", got)
+	}
+	if got := countRecords(buf.String(), "WARN", "hot"); got != 12 {
+		t.Errorf("expected 12 warn records, got %d", got)
+	}
+	if got := countRecords(buf.String(), "INFO", "cold"); got != 1 {
+		t.Errorf("expected 1 cold record, got %d", got)
+	}
+}
+
+func TestOptionSamplingCallSites(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionSampling(time.Hour, 1, 0),
+		blog.OptionDroppedReportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 10 {
+		logger.Info(context.Background(), "hot")
+		logger.Info(context.Background(), "hot")
+	}
+
+	// The first record of each of two call sites.
+	if got := countRecords(buf.String(), "INFO", "hot"); got != 2 {
+		t.Errorf("expected 2 records, got %d", got)
+	}
+}
+
+func TestOptionRateLimit(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionRateLimit(blog.LevelDebug, 0.001, 5),
+		blog.OptionDroppedReportInterval(time.Hour),
+		core.OptionLimitsClock(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 20 {
+		logger.Debug(context.Background(), "limited")
+		logger.Info(context.Background(), "unlimited")
+	}
+	if got := countRecords(buf.String(), "DEBUG", "limited"); got != 5 {
+		t.Errorf("expected a burst of 5 records, got %d", got)
+	}
+	if got := countRecords(buf.String(), "INFO", "unlimited"); got != 20 {
+		t.Errorf("expected 20 records of other levels, got %d", got)
+	}
+	if countRecords(buf.String(), "WARN", "log") != 0 {
+		t.Errorf("dropped records must not be reported before the interval:\n%s", buf.String())
+	}
+
+	now = now.Add(time.Hour)
+	logger.Debug(context.Background(), "limited")
+	if countRecords(buf.String(), "WARN", "log") != 1 || !strings.Contains(buf.String(), `"dropped": 15`) {
+		t.Errorf("missing a summary of dropped records:\n%s", buf.String())
+	}
+}
+
+func TestDroppedReportSync(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionRateLimit(blog.LevelInfo, 0.001, 1),
+		blog.OptionDroppedReportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 4 {
+		logger.Info(context.Background(), "limited")
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if countRecords(buf.String(), "WARN", "log") != 1 || !strings.Contains(buf.String(), `"dropped": 3`) {
+		t.Errorf("missing a summary of dropped records:\n%s", buf.String())
+	}
+
+	// Nothing is left to report.
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := countRecords(buf.String(), "WARN", "log"); got != 1 {
+		t.Errorf("expected a single summary, got %d", got)
+	}
+}
+
+// limitsTestReports notifies of summaries of dropped records written.
+type limitsTestReports struct {
+	reports chan string
+}
+
+func (w *limitsTestReports) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	if _, err := blog.NewPrettyWriter(&buf).Write(p); err != nil {
+		return 0, err
+	}
+	if strings.Contains(buf.String(), "log records dropped") {
+		select {
+		case w.reports <- buf.String():
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func TestDroppedReportTimer(t *testing.T) {
+	w := &limitsTestReports{reports: make(chan string, 1)}
+	logger, err := blog.NewLogger(
+		w,
+		blog.OptionRateLimit(blog.LevelInfo, 0.001, 1),
+		blog.OptionDroppedReportInterval(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst followed by silence.
+	for range 3 {
+		logger.Info(context.Background(), "limited")
+	}
+
+	select {
+	case report := <-w.reports:
+		if !strings.Contains(report, `"dropped": 2`) {
+			t.Errorf("unexpected summary of dropped records:\n%s", report)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("dropped records were not reported")
+	}
+}
+
+func TestOptionLimitsValidation(t *testing.T) {
+	if _, err := blog.NewLogger(&bytes.Buffer{}, blog.OptionSampling(0, 1, 1)); err == nil {
+		t.Error("zero tick must be rejected")
+	}
+	if _, err := blog.NewLogger(&bytes.Buffer{}, blog.OptionRateLimit(blog.LevelInfo, 10, 0)); err == nil {
+		t.Error("zero burst must be rejected")
+	}
+}
+
+func countRecords(output, level, msg string) int {
+	var res int
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[2] == level && fields[3] == msg {
+			res++
+		}
+	}
+
+	return res
+}
@@ -2,6 +2,10 @@ package core
 
 import (
 	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
 )
 
 // OptionApplier is implemented by implementations controlling their aspection of [Logger] setup.
@@ -22,6 +26,97 @@ func OptionLogFromLevel(l LoggingLevel) OptionApplier {
 	}
 }
 
+// OptionLevelVar logger will only log events with level starting from the current level of v.
+// It overrides [OptionLogFromLevel].
+func OptionLevelVar(v *LevelVar) OptionApplier {
+	return &optionLevelVar{
+		v: v,
+	}
+}
+
+// OptionLevelTable logger will only log events with level starting from the level of
+// its component in the table, see [Logger.Named]. It overrides [OptionLevelVar] and [OptionLogFromLevel].
+func OptionLevelTable(t *LevelTable) OptionApplier {
+	return &optionLevelTable{
+		t: t,
+	}
+}
+
+// OptionFatalHook logger will call the hook instead of os.Exit(1) in [Logger.Fatal].
+// The hook is called after the record is written and the writer is synced.
+func OptionFatalHook(hook func()) OptionApplier {
+	return &optionFatalHook{
+		hook: hook,
+	}
+}
+
+// OptionFatalStack logger will log the stack in [Logger.Fatal] the same way [LogPanic] does.
+func OptionFatalStack() OptionApplier {
+	return &optionFatalStack{}
+}
+
+// OptionStackAllGoroutines logger will log stacks of all goroutines in [Logger.Fatal]
+// and [LogRecovered] rather than of the current one.
+func OptionStackAllGoroutines() OptionApplier {
+	return &optionStackAllGoroutines{}
+}
+
+// OptionRedactKeys logger will mask values of attrs whose keys match any of the patterns,
+// including attrs nested in groups and context of errors. Patterns use [path.Match] syntax
+// and are matched case-insensitively, like "*password*" or "*token". Masked values never
+// get into records, viewers show them as [REDACTED].
+func OptionRedactKeys(patterns ...string) OptionApplier {
+	return &optionRedactKeys{
+		patterns: patterns,
+	}
+}
+
+// OptionHooks logger will pass records through the hooks before they are written.
+// Hooks of several options are called in order of the options.
+func OptionHooks(hooks ...Hook) OptionApplier {
+	return &optionHooks{
+		hooks: hooks,
+	}
+}
+
+// OptionErrorFingerprints logger will put fingerprints of errors into their contexts
+// with the @fingerprint key. See [ErrorFingerprint] for details.
+func OptionErrorFingerprints() OptionApplier {
+	return &optionErrorFingerprints{}
+}
+
+// OptionSampling logger will only log first records with the same level, message and call site
+// in each tick and then every thereafter-th of them. Zero thereafter means the rest is dropped.
+// Records dropped are counted and reported periodically, see [OptionDroppedReportInterval].
+func OptionSampling(tick time.Duration, first, thereafter int) OptionApplier {
+	return &optionSampling{
+		tick:       tick,
+		first:      first,
+		thereafter: thereafter,
+	}
+}
+
+// OptionRateLimit logger will log records of the given level with the given rate per second,
+// allowing bursts of up to burst records. Records dropped are counted and reported periodically,
+// see [OptionDroppedReportInterval].
+func OptionRateLimit(level LoggingLevel, perSecond float64, burst int) OptionApplier {
+	return &optionRateLimit{
+		level:     level,
+		perSecond: perSecond,
+		burst:     burst,
+	}
+}
+
+// OptionDroppedReportInterval sets how often a warning with the number of records dropped
+// with [OptionSampling] and [OptionRateLimit] is logged, 10 seconds by default. It is logged
+// along with the first record, logged or dropped, after the interval, or by a timer when no
+// records come after a drop. [Logger.Sync] logs it right away.
+func OptionDroppedReportInterval(d time.Duration) OptionApplier {
+	return &optionDroppedReportInterval{
+		d: d,
+	}
+}
+
 type optionLogLocations struct{}
 
 func (e *optionLogLocations) String() string {
@@ -55,3 +150,209 @@ func (e *optionLogFrom) apply(l *Logger) error {
 	l.logFrom = e.l
 	return nil
 }
+
+type optionLevelVar struct {
+	v *LevelVar
+}
+
+func (e *optionLevelVar) String() string {
+	return "level var"
+}
+
+func (e *optionLevelVar) apply(l *Logger) error {
+	if e.v == nil {
+		return fmt.Errorf("level var must not be nil")
+	}
+
+	l.levelVar = e.v
+	return nil
+}
+
+type optionLevelTable struct {
+	t *LevelTable
+}
+
+func (e *optionLevelTable) String() string {
+	return "level table"
+}
+
+func (e *optionLevelTable) apply(l *Logger) error {
+	if e.t == nil {
+		return fmt.Errorf("level table must not be nil")
+	}
+
+	l.levelTable = e.t
+	return nil
+}
+
+type optionFatalHook struct {
+	hook func()
+}
+
+func (e *optionFatalHook) String() string {
+	return "fatal hook"
+}
+
+func (e *optionFatalHook) apply(l *Logger) error {
+	if e.hook == nil {
+		return fmt.Errorf("fatal hook must not be nil")
+	}
+
+	l.fatalHook = e.hook
+	return nil
+}
+
+type optionFatalStack struct{}
+
+func (e *optionFatalStack) String() string {
+	return "fatal stack"
+}
+
+func (e *optionFatalStack) apply(l *Logger) error {
+	l.fatalStack = true
+	return nil
+}
+
+type optionStackAllGoroutines struct{}
+
+func (e *optionStackAllGoroutines) String() string {
+	return "stack all goroutines"
+}
+
+func (e *optionStackAllGoroutines) apply(l *Logger) error {
+	l.stackAll = true
+	return nil
+}
+
+type optionHooks struct {
+	hooks []Hook
+}
+
+func (e *optionHooks) String() string {
+	return "hooks"
+}
+
+func (e *optionHooks) apply(l *Logger) error {
+	for i, hook := range e.hooks {
+		if hook == nil {
+			return fmt.Errorf("hook #%d is nil", i)
+		}
+	}
+
+	l.hooks = append(l.hooks[:len(l.hooks):len(l.hooks)], e.hooks...)
+	return nil
+}
+
+type optionErrorFingerprints struct{}
+
+func (e *optionErrorFingerprints) String() string {
+	return "error fingerprints"
+}
+
+func (e *optionErrorFingerprints) apply(l *Logger) error {
+	l.errorFingerprints = true
+	return nil
+}
+
+type optionSampling struct {
+	tick       time.Duration
+	first      int
+	thereafter int
+}
+
+func (e *optionSampling) String() string {
+	return "sampling"
+}
+
+func (e *optionSampling) apply(l *Logger) error {
+	if e.tick <= 0 {
+		return fmt.Errorf("tick must be positive, got %s", e.tick)
+	}
+	if e.first < 0 || e.thereafter < 0 {
+		return fmt.Errorf("first and thereafter must not be negative, got %d and %d", e.first, e.thereafter)
+	}
+
+	l.limitsSetup().sampling = &logSampling{
+		tick:       int64(e.tick),
+		first:      uint64(e.first),
+		thereafter: uint64(e.thereafter),
+	}
+	return nil
+}
+
+type optionRateLimit struct {
+	level     LoggingLevel
+	perSecond float64
+	burst     int
+}
+
+func (e *optionRateLimit) String() string {
+	return "rate limit " + e.level.String()
+}
+
+func (e *optionRateLimit) apply(l *Logger) error {
+	switch e.level {
+	case LoggingLevelTrace:
+	case LoggingLevelDebug:
+	case LoggingLevelInfo:
+	case LoggingLevelWarning:
+	case LoggingLevelError:
+	default:
+		return fmt.Errorf("logging-level-uknown[%d]", e.level)
+	}
+	if e.perSecond <= 0 {
+		return fmt.Errorf("rate must be positive, got %g", e.perSecond)
+	}
+	if e.burst < 1 {
+		return fmt.Errorf("burst must be at least 1, got %d", e.burst)
+	}
+
+	interval := int64(float64(time.Second) / e.perSecond)
+	l.limitsSetup().rates[e.level/10] = &logRate{
+		interval:  interval,
+		tolerance: interval * int64(e.burst),
+	}
+	return nil
+}
+
+type optionDroppedReportInterval struct {
+	d time.Duration
+}
+
+func (e *optionDroppedReportInterval) String() string {
+	return "dropped report interval"
+}
+
+func (e *optionDroppedReportInterval) apply(l *Logger) error {
+	if e.d <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", e.d)
+	}
+
+	l.limitsSetup().reportInterval = int64(e.d)
+	return nil
+}
+
+type optionRedactKeys struct {
+	patterns []string
+}
+
+func (e *optionRedactKeys) String() string {
+	return "redact keys"
+}
+
+func (e *optionRedactKeys) apply(l *Logger) error {
+	rules := &redactRules{}
+	if l.redact != nil {
+		rules.patterns = slices.Clone(l.redact.patterns)
+	}
+	for _, pattern := range e.patterns {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		rules.patterns = append(rules.patterns, pattern)
+	}
+
+	l.redact = rules
+	return nil
+}
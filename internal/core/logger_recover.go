@@ -0,0 +1,187 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	// panicInfoMaxDepth limits nesting of groups made of recovered values.
+	panicInfoMaxDepth = 4
+
+	// panicInfoMaxItems limits items of slices and maps of recovered values. The number of
+	// items left out is put with the @truncated key.
+	panicInfoMaxItems = 32
+)
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and logged with [LogPanic].
+func Go(ctx context.Context, log *Logger, fn func(ctx context.Context)) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.logRecovered(ctx, 0, r)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// LogRecovered logs the recovered value with the current stack using [LogPanic],
+// see also [OptionStackAllGoroutines].
+// It is meant for recovery routines, since recover itself must be called directly
+// by deferred functions:
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        core.LogRecovered(ctx, logger, r)
+//	    }
+//	}()
+//
+// It must be called by the deferred function itself: the location of the record,
+// see [OptionLogLocations], is the one of the panic then.
+func LogRecovered(ctx context.Context, log *Logger, r any) {
+	log.logRecovered(ctx, 1, r)
+}
+
+// logRecovered is [LogRecovered] with the location skip frames above the caller.
+func (l *Logger) logRecovered(ctx context.Context, skip int, r any) {
+	l.logPanic(ctx, skip+1, l.stacktrace(), LogPanicInfo(r))
+}
+
+// RecoverHandler is an [http.Handler] that recovers panics of the wrapped handler,
+// logs them with [LogPanic] along with the request info and responds with
+// 500 Internal Server Error.
+type RecoverHandler struct {
+	log     *Logger
+	next    http.Handler
+	repanic bool
+}
+
+// NewRecoverHandler wraps the handler.
+func NewRecoverHandler(log *Logger, next http.Handler) *RecoverHandler {
+	return &RecoverHandler{
+		log:  log,
+		next: next,
+	}
+}
+
+// WithRepanic returns a copy of the handler that panics again with the recovered value
+// after it is logged, to let outer middlewares or the server itself see it.
+func (h *RecoverHandler) WithRepanic() *RecoverHandler {
+	c := *h
+	c.repanic = true
+	return &c
+}
+
+func (h *RecoverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		if v == http.ErrAbortHandler {
+			// This one is meant to abort a response silently.
+			panic(v)
+		}
+
+		log := h.log.With(Group(
+			"request",
+			Str("method", r.Method),
+			Str("url", r.URL.String()),
+			Str("remote-addr", r.RemoteAddr),
+		))
+		log.logRecovered(r.Context(), 0, v)
+		if h.repanic {
+			panic(v)
+		}
+
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}()
+
+	h.next.ServeHTTP(w, r)
+}
+
+// reflectAttr makes an [Attr] of values of types [LogPanicInfo] knows nothing about.
+// Structs and maps become groups, values of named basic types become values of their
+// underlying types.
+func reflectAttr(key string, v reflect.Value, depth int) Attr {
+	if !v.IsValid() {
+		return Str(key, "nil")
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return Str(key, "nil")
+		}
+		return reflectAttr(key, v.Elem(), depth)
+	case reflect.Bool:
+		return Bool(key, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int64(key, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Uint64(key, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return Flt64(key, v.Float())
+	case reflect.String:
+		return Str(key, v.String())
+	}
+
+	if depth >= panicInfoMaxDepth || !v.CanInterface() {
+		return Str(key, fmt.Sprintf("%#v", v))
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return Time(key, t)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		attrs := make([]Attr, 0, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			attrs = append(attrs, reflectAttr(field.Name, v.Field(i), depth+1))
+		}
+		return Group(key, attrs...)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		attrs := make([]Attr, 0, min(len(keys), panicInfoMaxItems)+1)
+		for i, k := range keys {
+			if i == panicInfoMaxItems {
+				attrs = append(attrs, Int("@truncated", len(keys)-i))
+				break
+			}
+			if k.String() == "" {
+				continue
+			}
+			attrs = append(attrs, reflectAttr(k.String(), v.MapIndex(k), depth+1))
+		}
+		return Group(key, attrs...)
+	case reflect.Slice, reflect.Array:
+		attrs := make([]Attr, 0, min(v.Len(), panicInfoMaxItems)+1)
+		for i := range v.Len() {
+			if i == panicInfoMaxItems {
+				attrs = append(attrs, Int("@truncated", v.Len()-i))
+				break
+			}
+			attrs = append(attrs, reflectAttr(strconv.Itoa(i), v.Index(i), depth+1))
+		}
+		return Group(key, attrs...)
+	}
+
+	return Str(key, fmt.Sprintf("%#v", v.Interface()))
+}
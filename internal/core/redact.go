@@ -0,0 +1,136 @@
+package core
+
+import (
+	"encoding/binary"
+	"path"
+	"strings"
+	"unsafe"
+)
+
+// redactRules mask values of attrs with matching keys when records are serialized.
+// Masked values are replaced with [ValueKindSecret] nodes, just like [Secret] attrs are.
+type redactRules struct {
+	// patterns are lowercased [path.Match] patterns.
+	patterns []string
+}
+
+// match checks if the key matches any pattern. Keys are matched case-insensitively.
+func (r *redactRules) match(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendSerialized works like [AppendSerialized] and masks values of attrs with matching keys,
+// including ones nested in groups and in context of errors.
+func (r *redactRules) appendSerialized(src []byte, attr Attr) []byte {
+	if attr.kind>>8 != 0 {
+		// Predefined keys are never masked.
+		return AppendSerialized(src, attr)
+	}
+
+	switch attr.kind {
+	case ValueKindGroup:
+		if r.match(attr.Key) {
+			return appendSecretNode(src, attr.Key)
+		}
+		src = appendSerializedHead(src, attr)
+		v := unsafe.Slice((*Attr)(unsafe.Pointer(attr.Value.srl.(*groupPtr))), attr.Value.num)
+		for _, vv := range v {
+			src = r.appendSerialized(src, vv)
+		}
+		return append(src, byte(ValueKindGroupEnd))
+	case ValueKindError, ValueKindErrorEmbed:
+		// Errors are too valuable to be masked entirely, their context is masked instead.
+		errPtr := (*Error)(unsafe.Pointer(attr.Value.srl.(*errorPtr)))
+		src = appendSerializedHead(src, attr)
+		if attr.kind == ValueKindErrorEmbed {
+			src = binary.AppendUvarint(src, uint64(len(errPtr.text)))
+			src = append(src, errPtr.text...)
+		}
+		src = r.appendPayload(src, errPtr.payload)
+		return append(src, byte(ValueKindGroupEnd), byte(ValueKindGroupEnd))
+	case ValueKindSecret:
+		return AppendSerialized(src, attr)
+	}
+
+	if r.match(attr.Key) {
+		return appendSecretNode(src, attr.Key)
+	}
+	return AppendSerialized(src, attr)
+}
+
+// appendPayload appends serialized payload of an error masking values with matching keys.
+func (r *redactRules) appendPayload(dst []byte, payload []byte) []byte {
+	for len(payload) > 0 {
+		kind, key, value, rest := splitPayloadNode(payload)
+		node := payload[:len(payload)-len(rest)]
+		payload = rest
+
+		switch {
+		case kind == ValueKindRemoteNode:
+			// The length of the remote payload is a part of the node, it must be
+			// computed once the remote payload is masked.
+			text, nested := splitRemoteNodeValue(value)
+			masked := r.appendPayload(nil, payload[:nested])
+			payload = payload[nested:]
+
+			dst = append(dst, node[:len(node)-len(value)]...)
+			dst = binary.AppendUvarint(dst, uint64(len(text)))
+			dst = append(dst, text...)
+			dst = binary.AppendUvarint(dst, uint64(len(masked)))
+			dst = append(dst, masked...)
+		case !isRedactableNode(kind, node) || !r.match(unsafe.String(unsafe.SliceData(key), len(key))):
+			dst = append(dst, node...)
+		case kind == ValueKindGroup:
+			dst = appendSecretNode(dst, unsafe.String(unsafe.SliceData(key), len(key)))
+			payload = skipPayloadGroup(payload)
+		default:
+			dst = appendSecretNode(dst, unsafe.String(unsafe.SliceData(key), len(key)))
+		}
+	}
+
+	return dst
+}
+
+// isRedactableNode checks if the serialized node is a context value having a regular key.
+func isRedactableNode(kind ValueKind, node []byte) bool {
+	if kind != ValueKindGroup && (kind < ValueKindBool || kind == ValueKindSecret) {
+		return false
+	}
+
+	// Predefined keys have zero length followed by their index, see [appendSerializedHead].
+	length, size := binary.Uvarint(node[1:])
+	return size > 0 && length > 0
+}
+
+// skipPayloadGroup skips the rest of the group up to and including its [ValueKindGroupEnd].
+func skipPayloadGroup(payload []byte) []byte {
+	depth := 1
+	for depth > 0 {
+		var kind ValueKind
+		kind, _, _, payload = splitPayloadNode(payload)
+		switch kind {
+		case ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode,
+			ValueKindJustContextNode, ValueKindJustContextInheritedNode,
+			ValueKindGroup, ValueKindError, ValueKindErrorEmbed, ValueKindRemoteNode:
+			depth++
+		case ValueKindGroupEnd:
+			depth--
+		}
+	}
+
+	return payload
+}
+
+func appendSecretNode(src []byte, key string) []byte {
+	return AppendSerialized(src, Attr{
+		Key:  key,
+		kind: ValueKindSecret,
+	})
+}
@@ -0,0 +1,127 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestOptionRedactKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(
+		blog.NewPrettyWriter(&buf),
+		blog.OptionRedactKeys("*password*", "token"),
+		blog.OptionRedactKeys("credentials"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote := core.NewError("denied").Str("token", "remote-token").Str("user", "alice")
+	data, err := core.MarshalErrorBinary(remote, "auth-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteErr, err := core.UnmarshalErrorBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loginErr := core.NewError("login").Str("Token", "error-token").Int("attempt", 2)
+	loginErr = core.WrapError(loginErr, "authenticate").Strs("credentials", []string{"error-key"})
+
+	logger.With(blog.Str("db-password", "with-password")).Info(
+		context.Background(),
+		"login",
+		blog.Secret("api-key", "secret-key"),
+		blog.Str("PASSWORD", "hunter2"),
+		blog.Group("auth", blog.Str("token", "group-token"), blog.Str("user", "bob")),
+		blog.Group("credentials", blog.Str("key", "group-key")),
+		blog.Err(loginErr),
+		blog.Error("remote", core.WrapError(remoteErr, "call auth")),
+	)
+	logger.Info(context.Background(), "connect", blog.Str("password", "inline-password"), blog.Int("port", 5432))
+
+	out := buf.String()
+	for _, secret := range []string{
+		"secret-key",
+		"hunter2",
+		"with-password",
+		"group-token",
+		"group-key",
+		"error-token",
+		"error-key",
+		"remote-token",
+		"inline-password",
+	} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q must be redacted:\n%s", secret, out)
+		}
+	}
+	for _, visible := range []string{
+		"api-key: [REDACTED]",
+		"PASSWORD: [REDACTED]",
+		"user: bob",
+		"attempt: 2",
+		"Token: [REDACTED]",
+		"user: alice",
+		"call auth: denied",
+		"authenticate: login",
+		`{"password": "[REDACTED]", "port": 5432}`,
+	} {
+		if !strings.Contains(out, visible) {
+			t.Errorf("%q is expected in the output:\n%s", visible, out)
+		}
+	}
+}
+
+func TestOptionRedactKeysInvalidPattern(t *testing.T) {
+	if _, err := blog.NewLogger(blog.NewPrettyWriter(&bytes.Buffer{}), blog.OptionRedactKeys("[")); err == nil {
+		t.Fatal("invalid pattern must be rejected")
+	}
+}
+
+// redactTestViewer only collects strings of the context.
+type redactTestViewer struct {
+	core.RecordViewer
+	ctx *redactTestContext
+}
+
+func (v *redactTestViewer) Time(time.Time)                            {}
+func (v *redactTestViewer) Level(core.LoggingLevel)                   {}
+func (v *redactTestViewer) Message([]byte)                            {}
+func (v *redactTestViewer) ContextVisitor() core.RecordContextVisitor { return v.ctx }
+
+// redactTestContext does not implement [core.RecordContextSecretVisitor].
+type redactTestContext struct {
+	core.RecordContextVisitor
+	strs map[string]string
+}
+
+func (c *redactTestContext) Str(key []byte, value []byte) {
+	c.strs[string(key)] = string(value)
+}
+
+func (c *redactTestContext) Finish() {}
+
+func TestSecretVisitorFallback(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(context.Background(), "login", blog.Secret("password", "hunter2"))
+
+	ctx := &redactTestContext{strs: map[string]string{}}
+	if err := core.ProcessRecord(buf.Bytes(), &redactTestViewer{ctx: ctx}); err != nil {
+		t.Fatal(err)
+	}
+	if got := ctx.strs["password"]; got != core.RedactedValue {
+		t.Errorf("expected %q for the secret, got %q", core.RedactedValue, got)
+	}
+}
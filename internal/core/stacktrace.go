@@ -0,0 +1,156 @@
+package core
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// StackGoroutine is a goroutine of a stack trace in the format of [runtime/debug.Stack].
+type StackGoroutine struct {
+	Header    string // Like "goroutine 8 [running]".
+	Frames    []StackFrame
+	CreatedBy *StackFrame
+	CreatedIn int // Goroutine ID of the creator, -1 when unknown.
+}
+
+// StackFrame is a frame of a goroutine stack.
+type StackFrame struct {
+	Function string // Like main.(*server).handle, without arguments.
+	File     string
+	Line     int
+	Offset   uint64
+}
+
+// ParseStacktrace parses stack traces made with [runtime/debug.Stack] or [runtime.Stack].
+// Lines it cannot understand are skipped. Strings of the result refer to the text.
+func ParseStacktrace(text []byte) []StackGoroutine {
+	var res []StackGoroutine
+	var cur *StackGoroutine
+	var fn string
+	var created bool
+
+	for line := range bytes.Lines(text) {
+		line = bytes.TrimRight(line, "\r\n")
+		str := unsafe.String(unsafe.SliceData(line), len(line))
+
+		switch {
+		case len(str) == 0:
+			cur = nil
+		case strings.HasPrefix(str, "goroutine ") && strings.HasSuffix(str, ":"):
+			res = append(res, StackGoroutine{
+				Header:    strings.TrimSuffix(str, ":"),
+				CreatedIn: -1,
+			})
+			cur = &res[len(res)-1]
+			fn = ""
+		case cur == nil:
+		case str[0] == '\t':
+			if fn == "" {
+				continue
+			}
+			frame := parseStackFrameLocation(fn, str[1:])
+			if created {
+				cur.CreatedBy = &frame
+			} else {
+				cur.Frames = append(cur.Frames, frame)
+			}
+			fn = ""
+		case strings.HasPrefix(str, "created by "):
+			fn, created = strings.TrimPrefix(str, "created by "), true
+			if before, after, ok := strings.Cut(fn, " in goroutine "); ok {
+				fn = before
+				if id, err := strconv.Atoi(after); err == nil {
+					cur.CreatedIn = id
+				}
+			}
+		case strings.HasPrefix(str, "..."):
+			// Like "...additional frames elided...".
+		default:
+			fn, created = str, false
+			if strings.HasSuffix(fn, ")") {
+				if pos := strings.LastIndexByte(fn, '('); pos > 0 {
+					fn = fn[:pos]
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// parseStackFrameLocation parses locations like "/src/main.go:12 +0x1d".
+func parseStackFrameLocation(fn, loc string) StackFrame {
+	res := StackFrame{
+		Function: fn,
+		File:     loc,
+	}
+
+	if before, after, found := strings.Cut(loc, " +0x"); found {
+		if v, err := strconv.ParseUint(after, 16, 64); err == nil {
+			res.Offset = v
+		}
+		loc = before
+		res.File = loc
+	}
+	if pos := strings.LastIndexByte(loc, ':'); pos > 0 {
+		if v, err := strconv.Atoi(loc[pos+1:]); err == nil {
+			res.File = loc[:pos]
+			res.Line = v
+		}
+	}
+
+	return res
+}
+
+// stacktraceAttr makes a group with the predefined @stack key of the stack trace
+// in the format of [runtime/debug.Stack]:
+//
+//	@stack
+//	└─ goroutine 8 [running]
+//	   ├─ frames
+//	   │  ├─ 0
+//	   │  │  ├─ func: main.main
+//	   │  │  ├─ file: /src/main.go
+//	   │  │  ├─ line: 12
+//	   │  │  └─ offset: 29
+//	   │  └─ …
+//	   └─ created-by
+//	      ├─ func: …
+//	      └─ goroutine: 1
+func stacktraceAttr(text []byte) Attr {
+	goroutines := ParseStacktrace(text)
+
+	attrs := make([]Attr, 0, len(goroutines))
+	for _, g := range goroutines {
+		frames := make([]Attr, 0, len(g.Frames))
+		for i, frame := range g.Frames {
+			frames = append(frames, Group(strconv.Itoa(i), frame.attrs()...))
+		}
+
+		gattrs := []Attr{Group("frames", frames...)}
+		if g.CreatedBy != nil {
+			created := g.CreatedBy.attrs()
+			if g.CreatedIn >= 0 {
+				created = append(created, Int("goroutine", g.CreatedIn))
+			}
+			gattrs = append(gattrs, Group("created-by", created...))
+		}
+		attrs = append(attrs, Group(g.Header, gattrs...))
+	}
+
+	res := Group("@stack", attrs...)
+	res.Key = ""
+	res.kind |= ValuePredefinedNameStack
+	return res
+}
+
+func (f StackFrame) attrs() []Attr {
+	return []Attr{
+		Str("func", f.Function),
+		Str("file", f.File),
+		Int("line", f.Line),
+		Uint64("offset", f.Offset),
+	}
+}
@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/blog"
+	"github.com/sirkon/blog/internal/core"
+)
+
+const testStacktrace = `goroutine 8 [running]:
+main.(*server).handle(0xc000010000, {0x5a1b20, 0xc0000a2000})
+	/src/server.go:42 +0x1d
+main.main.func1()
+	/src/main.go:12 +0x25
+created by main.main in goroutine 1
+	/src/main.go:10 +0x3f
+
+goroutine 1 [chan receive]:
+main.main()
+	/src/main.go:15
+...additional frames elided...
+`
+
+func TestLogPanicStacktrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := blog.NewLogger(blog.NewPrettyWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core.LogPanic(context.Background(), logger, []byte(testStacktrace), core.LogPanicInfo("boom"))
+
+	want := `.... goroutine 8 [running]:
+.... main.(*server).handle
+.... 	/src/server.go:42 +0x1d
+.... main.main.func1
+.... 	/src/main.go:12 +0x25
+.... created by main.main in goroutine 1
+.... 	/src/main.go:10 +0x3f
+.... 
+.... goroutine 1 [chan receive]:
+.... main.main
+.... 	/src/main.go:15
+`
+	out := buf.String()
+	if !strings.HasSuffix(out, want) {
+		t.Errorf("unexpected stack trace rendering:\n%s", out)
+	}
+	if !strings.Contains(out, `PANIC panic  {"recovered": "boom"}`) {
+		t.Errorf("the message and the info must be rendered as usual:\n%s", out)
+	}
+}
@@ -16,6 +16,7 @@ const (
 	ErrorProcessingStageNew
 	ErrorProcessingStageWrap
 	ErrorProcessingStageContext
+	ErrorProcessingStageRemote
 )
 
 // RecordViewer to receive record elements.
@@ -78,6 +79,16 @@ type RecordContextVisitor interface {
 	Finish()
 }
 
+// RecordContextSecretVisitor can be implemented by a [RecordContextVisitor] to receive keys
+// whose values were redacted, see [Secret] and [OptionRedactKeys]. Visitors not implementing it
+// get these keys with [RedactedValue] strings.
+type RecordContextSecretVisitor interface {
+	Secret(key []byte)
+}
+
+// RedactedValue is reported instead of values of secrets.
+const RedactedValue = "[REDACTED]"
+
 func ProcessRecord(line []byte, viewer RecordViewer) (err error) {
 	// defer func() {
 	//	if r := recover(); r != nil {
@@ -151,6 +162,46 @@ func ProcessRecord(line []byte, viewer RecordViewer) (err error) {
 	return nil
 }
 
+// RecordLevel returns a level of the record without decoding it, checksum is not checked either.
+// Returns false if the data does not look like a record.
+func RecordLevel(record []byte) (LoggingLevel, bool) {
+	if len(record) < 5 || record[0] != 0xFF {
+		return 0, false
+	}
+
+	_, size := binary.Uvarint(record[5:])
+	if size <= 0 {
+		return 0, false
+	}
+
+	// The level follows the version and the time.
+	pos := 5 + size + 2 + 8
+	if pos >= len(record) {
+		return 0, false
+	}
+	return LoggingLevel(record[pos]), true
+}
+
+// RecordTime returns a time of the record without decoding it, checksum is not checked either.
+// Returns false if the data does not look like a record.
+func RecordTime(record []byte) (time.Time, bool) {
+	if len(record) < 5 || record[0] != 0xFF {
+		return time.Time{}, false
+	}
+
+	_, size := binary.Uvarint(record[5:])
+	if size <= 0 {
+		return time.Time{}, false
+	}
+
+	// The time follows the version.
+	pos := 5 + size + 2
+	if pos+8 > len(record) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.LittleEndian.Uint64(record[pos:]))), true
+}
+
 type payloadDeconstructor struct {
 	hasErrors         bool
 	stack             []ValueKind
@@ -158,6 +209,9 @@ type payloadDeconstructor struct {
 	embedErrText      []byte
 	errTextLen        int
 	errTextInProgress bool
+	// errTextMuted is a depth of the stack of the outermost remote stage node. Nested stages
+	// of a remote error must not contribute into the error text: the remote text is already there.
+	errTextMuted int
 }
 
 func (d *payloadDeconstructor) deconstructPayload(payload []byte, visitor RecordContextVisitor) {
@@ -188,6 +242,11 @@ func (d *payloadDeconstructor) deconstructNode(payload []byte, visitor RecordCon
 		case ValueKindJustContextNode, ValueKindJustContextInheritedNode,
 			ValueKindNewNode, ValueKindWrapNode, ValueKindWrapInheritedNode:
 			visitor.LeaveErrorStage()
+		case ValueKindRemoteNode:
+			if d.errTextMuted == len(d.stack)+1 {
+				d.errTextMuted = 0
+			}
+			visitor.LeaveErrorStage()
 		case ValueKindError:
 			buf := make([]byte, 0, d.errTextLen+(len(d.errText)-1)*2)
 			for i := len(d.errText) - 1; i >= 0; i-- {
@@ -213,16 +272,7 @@ func (d *payloadDeconstructor) deconstructPayloadNode(
 	visitor RecordContextVisitor,
 ) []byte {
 	var key []byte
-	if payload[0] != 0 {
-		// String key.
-		key, payload = mustReadString(payload)
-	} else {
-		// Predefined key.
-		var knownIndex int
-		knownIndex, payload = mustReadUvarint(payload)
-		kkk := PredefinedKeys[knownIndex]
-		key = unsafe.Slice(unsafe.StringData(kkk), len(kkk))
-	}
+	key, payload = splitPayloadKey(kind, payload)
 
 	payload = d.deconstructPayloadNodeValue(payload, kind, key, visitor)
 	return payload
@@ -238,33 +288,31 @@ func (d *payloadDeconstructor) deconstructPayloadNodeValue(
 	case ValueKindNewNode:
 		d.stack = append(d.stack, kind)
 		visitor.EnterErrorStage(ErrorProcessingStageNew, key)
-		if d.errTextInProgress {
-			d.errText = append(d.errText, key)
-			d.errTextLen += len(key)
-		}
+		d.appendErrText(key)
 	case ValueKindWrapNode:
 		d.stack = append(d.stack, kind)
 		visitor.EnterErrorStage(ErrorProcessingStageWrap, key)
-		if d.errTextInProgress {
-			d.errText = append(d.errText, key)
-			d.errTextLen += len(key)
-		}
+		d.appendErrText(key)
 	case ValueKindWrapInheritedNode:
 		d.stack = append(d.stack, kind)
 		visitor.EnterErrorStage(ErrorProcessingStageWrap, key)
-		if d.errTextInProgress {
-			d.errText = append(d.errText, key)
-			d.errTextLen += len(key)
+		d.appendErrText(key)
+	case ValueKindRemoteNode:
+		var text []byte
+		text, payload = mustReadString(payload)
+		_, payload = mustReadUvarint(payload)
+		d.appendErrText(text)
+		d.stack = append(d.stack, kind)
+		if d.errTextMuted == 0 {
+			d.errTextMuted = len(d.stack)
 		}
+		visitor.EnterErrorStage(ErrorProcessingStageRemote, key)
 	case ValueKindLocationNode:
 		var line int
 		line, payload = mustReadUvarint(payload)
 		visitor.ErrorStageLocation(key, line)
 	case ValueKindForeignErrorText:
-		if d.errTextInProgress {
-			d.errText = append(d.errText, key)
-			d.errTextLen += len(key)
-		}
+		d.appendErrText(key)
 	case ValueKindBool:
 		var v uint8
 		v, payload = mustReadU8(payload)
@@ -434,6 +482,12 @@ func (d *payloadDeconstructor) deconstructPayloadNodeValue(
 		var value []byte
 		value, payload = mustReadString(payload)
 		visitor.RawError(key, value)
+	case ValueKindSecret:
+		if v, ok := visitor.(RecordContextSecretVisitor); ok {
+			v.Secret(key)
+		} else {
+			visitor.Str(key, []byte(RedactedValue))
+		}
 	default:
 		panic(fmt.Errorf("unknown value kind %s", kind))
 	}
@@ -441,6 +495,15 @@ func (d *payloadDeconstructor) deconstructPayloadNodeValue(
 	return payload
 }
 
+func (d *payloadDeconstructor) appendErrText(text []byte) {
+	if !d.errTextInProgress || d.errTextMuted != 0 {
+		return
+	}
+
+	d.errText = append(d.errText, text)
+	d.errTextLen += len(text)
+}
+
 func readUvarint(src []byte) (int, []byte, error) {
 	length, uvarintLength := binary.Uvarint(src)
 	if uvarintLength <= 0 {
@@ -2,6 +2,8 @@ package blog
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/sirkon/blog/internal/core"
 )
@@ -26,7 +28,9 @@ func NewLogger(w core.WriteSyncer, options ...core.OptionApplier) (*Logger, erro
 //	    blog.LogPanic(ctx, logger, debug.Stack(), info)
 //	}()
 //
-// Panic text payload will be stored in gzipped form as a message.
+// The stack trace is logged as a group with the @stack key.
+//
+// Use [Recover], [Go] or [NewRecoverHandler] to have this done for you.
 func LogPanic(ctx context.Context, log *Logger, stacktrace []byte, info Attr) {
 	core.LogPanic(ctx, log, stacktrace, info)
 }
@@ -47,10 +51,107 @@ func OptionLogFromLevel(l core.LoggingLevel) core.OptionApplier {
 	return core.OptionLogFromLevel(l)
 }
 
+// OptionLevelVar logger will only log from the current level of v and further.
+func OptionLevelVar(v *LevelVar) core.OptionApplier {
+	return core.OptionLevelVar(v)
+}
+
+// OptionLevelTable logger will only log from the level of its component in the table.
+func OptionLevelTable(t *LevelTable) core.OptionApplier {
+	return core.OptionLevelTable(t)
+}
+
+// OptionErrorFingerprints logger will put fingerprints of errors into their contexts.
+func OptionErrorFingerprints() core.OptionApplier {
+	return core.OptionErrorFingerprints()
+}
+
+// OptionSampling logger will only log first records with the same level, message and call site
+// in each tick and then every thereafter-th of them.
+func OptionSampling(tick time.Duration, first, thereafter int) core.OptionApplier {
+	return core.OptionSampling(tick, first, thereafter)
+}
+
+// OptionRateLimit logger will log records of the given level with the given rate per second,
+// allowing bursts of up to burst records.
+func OptionRateLimit(level core.LoggingLevel, perSecond float64, burst int) core.OptionApplier {
+	return core.OptionRateLimit(level, perSecond, burst)
+}
+
+// OptionDroppedReportInterval sets how often a warning with the number of dropped records is logged.
+func OptionDroppedReportInterval(d time.Duration) core.OptionApplier {
+	return core.OptionDroppedReportInterval(d)
+}
+
+// OptionFatalHook logger will call the hook instead of os.Exit(1) in [Logger.Fatal].
+func OptionFatalHook(hook func()) core.OptionApplier {
+	return core.OptionFatalHook(hook)
+}
+
+// OptionFatalStack logger will log the stack in [Logger.Fatal].
+func OptionFatalStack() core.OptionApplier {
+	return core.OptionFatalStack()
+}
+
+// OptionStackAllGoroutines logger will log stacks of all goroutines in [Logger.Fatal] and [Recover].
+func OptionStackAllGoroutines() core.OptionApplier {
+	return core.OptionStackAllGoroutines()
+}
+
+// OptionRedactKeys logger will mask values of attrs whose keys match any of the patterns, see [path.Match].
+func OptionRedactKeys(patterns ...string) core.OptionApplier {
+	return core.OptionRedactKeys(patterns...)
+}
+
+// OptionHooks logger will pass records through the hooks before they are written.
+func OptionHooks(hooks ...Hook) core.OptionApplier {
+	return core.OptionHooks(hooks...)
+}
+
+// Hook an alias for [core.Hook].
+type Hook = core.Hook
+
+// HookRecord an alias for [core.HookRecord].
+type HookRecord = core.HookRecord
+
+// HookFunc an alias for [core.HookFunc].
+type HookFunc = core.HookFunc
+
+// LevelVar an alias for [core.LevelVar].
+type LevelVar = core.LevelVar
+
+// NewLevelVar creates a level variable set to the given level.
+func NewLevelVar(level core.LoggingLevel) *LevelVar {
+	return core.NewLevelVar(level)
+}
+
+// LevelTable an alias for [core.LevelTable].
+type LevelTable = core.LevelTable
+
+// NewLevelTable creates a table of component levels with the given default level.
+func NewLevelTable(def core.LoggingLevel) *LevelTable {
+	return core.NewLevelTable(def)
+}
+
+// ParseLevel parses level names like INFO or debug.
+func ParseLevel(s string) (core.LoggingLevel, error) {
+	return core.ParseLoggingLevel(s)
+}
+
+// ToggleLevelOnSignal switches v between its current level and the given one on each of given signals.
+// It panics if no signals are given.
+//
+//	stop := blog.ToggleLevelOnSignal(levels, blog.LevelDebug, syscall.SIGUSR1)
+//	defer stop()
+func ToggleLevelOnSignal(v *LevelVar, level core.LoggingLevel, sig ...os.Signal) (stop func()) {
+	return core.ToggleLevelOnSignal(v, level, sig...)
+}
+
 const (
 	LevelTrace   = core.LoggingLevelTrace
 	LevelDebug   = core.LoggingLevelDebug
 	LevelInfo    = core.LoggingLevelInfo
 	LevelWarning = core.LoggingLevelWarning
 	LevelError   = core.LoggingLevelError
+	LevelFatal   = core.LoggingLevelFatal
 )
@@ -0,0 +1,257 @@
+package blog
+
+import (
+	"container/heap"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// MergeSource is a log to merge with an alias its records are tagged with.
+type MergeSource struct {
+	Alias  string
+	Reader io.Reader
+}
+
+// MergeReader merges records of several logs, like logs of processes of a service, into a single
+// sequence ordered by times of records.
+//
+// Times of records of a log are not strictly monotonic: a record made later may be written earlier,
+// see the [Logger]. The reader keeps records within the reorder window, see [MergeReader.WithReorderWindow],
+// to put such records in order. Records which are late by more than the window are returned as soon
+// as possible, out of order.
+//
+//	r := blog.NewMergeReader(
+//	    blog.MergeSource{Alias: "api", Reader: apiLog},
+//	    blog.MergeSource{Alias: "worker", Reader: workerLog},
+//	)
+//	w := blog.NewPrettyWriter(os.Stdout).WithSources("api", "worker")
+//	for {
+//	    record, source, err := r.Next()
+//	    if err != nil {
+//	        break
+//	    }
+//	    w.WriteSource(source, record)
+//	}
+type MergeReader struct {
+	sources []*mergeSource
+	window  time.Duration
+	pending mergeHeap
+	seq     uint64
+}
+
+type mergeSource struct {
+	alias  string
+	closer io.Closer
+	index  int
+	reader *RecordReader
+	done   bool
+	// latest is the latest time of records read so far.
+	latest time.Time
+}
+
+type mergeRecord struct {
+	time   time.Time
+	source *mergeSource
+	seq    uint64 // Keeps the order of records of the same time.
+	data   []byte
+}
+
+// NewMergeReader creates a [MergeReader] with a reorder window of one second.
+func NewMergeReader(sources ...MergeSource) *MergeReader {
+	res := &MergeReader{
+		window: time.Second,
+	}
+	for i, s := range sources {
+		res.sources = append(res.sources, &mergeSource{
+			alias:  s.Alias,
+			closer: closer(s.Reader),
+			index:  i,
+			reader: NewRecordReader(s.Reader),
+		})
+	}
+
+	return res
+}
+
+// OpenMergeReader opens log files to merge. Aliases of files are their names without extensions,
+// files with the same names are told apart by their parent directories, like a/app and b/app,
+// and by numbers of their occurrences if this is not enough, like app#2.
+func OpenMergeReader(paths ...string) (*MergeReader, error) {
+	aliases := mergeAliases(paths)
+	files := make([]*os.File, 0, len(paths))
+	sources := make([]MergeSource, 0, len(paths))
+	for i, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, core.WrapError(err, "open log file")
+		}
+
+		files = append(files, file)
+		sources = append(sources, MergeSource{
+			Alias:  aliases[i],
+			Reader: file,
+		})
+	}
+
+	return NewMergeReader(sources...), nil
+}
+
+// mergeAliases returns unique aliases of log files.
+func mergeAliases(paths []string) []string {
+	aliases := make([]string, len(paths))
+	counts := map[string]int{}
+	for i, path := range paths {
+		name := filepath.Base(path)
+		aliases[i] = strings.TrimSuffix(name, filepath.Ext(name))
+		counts[aliases[i]]++
+	}
+
+	for i, path := range paths {
+		if counts[aliases[i]] > 1 {
+			aliases[i] = filepath.Base(filepath.Dir(path)) + "/" + aliases[i]
+		}
+	}
+
+	seen := map[string]int{}
+	for i, alias := range aliases {
+		seen[alias]++
+		if n := seen[alias]; n > 1 {
+			aliases[i] = alias + "#" + strconv.Itoa(n)
+		}
+	}
+
+	return aliases
+}
+
+// Close closes readers of logs implementing [io.Closer], like files opened with [OpenMergeReader].
+func (m *MergeReader) Close() error {
+	var errs []error
+	for _, s := range m.sources {
+		if s.closer == nil {
+			continue
+		}
+		if err := s.closer.Close(); err != nil {
+			errs = append(errs, core.WrapError(err, "close "+s.alias))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithReorderWindow sets how late records of a log may be compared to records written before them.
+// Larger windows tolerate more disorder at the cost of keeping more records in memory.
+func (m *MergeReader) WithReorderWindow(window time.Duration) *MergeReader {
+	m.window = max(window, 0)
+	return m
+}
+
+// Next returns the next record and the alias of its log.
+// Returns [io.EOF] when there are no more records. Errors of reading are annotated with aliases of logs.
+func (m *MergeReader) Next() (record []byte, source string, err error) {
+	for {
+		if len(m.pending) > 0 && m.ready(m.pending[0].time) {
+			rec := heap.Pop(&m.pending).(*mergeRecord)
+			return rec.data, rec.source.alias, nil
+		}
+
+		// Read more from the log holding the merge back the most.
+		var next *mergeSource
+		for _, s := range m.sources {
+			if !s.done && (next == nil || s.latest.Before(next.latest)) {
+				next = s
+			}
+		}
+		if next == nil {
+			// All logs are read and all pending records are returned.
+			return nil, "", io.EOF
+		}
+		if err := m.read(next); err != nil {
+			return nil, next.alias, err
+		}
+	}
+}
+
+// ready tells if no record with an earlier or the same time can come from logs being read.
+func (m *MergeReader) ready(t time.Time) bool {
+	for _, s := range m.sources {
+		if !s.done && !s.latest.Add(-m.window).After(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *MergeReader) read(s *mergeSource) error {
+	data, err := s.reader.Next()
+	if err != nil {
+		s.done = true
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return core.WrapError(err, "read "+s.alias)
+	}
+
+	t, ok := core.RecordTime(data)
+	if !ok {
+		return core.NewError("read " + s.alias + ": invalid record header")
+	}
+	if t.After(s.latest) {
+		s.latest = t
+	}
+
+	m.seq++
+	heap.Push(&m.pending, &mergeRecord{
+		time:   t,
+		source: s,
+		seq:    m.seq,
+		data:   append([]byte(nil), data...),
+	})
+	return nil
+}
+
+func closer(r io.Reader) io.Closer {
+	if c, ok := r.(io.Closer); ok {
+		return c
+	}
+
+	return nil
+}
+
+// mergeHeap orders records by times, then by sources, then by the order of reading.
+type mergeHeap []*mergeRecord
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if !a.time.Equal(b.time) {
+		return a.time.Before(b.time)
+	}
+	if a.source.index != b.source.index {
+		return a.source.index < b.source.index
+	}
+	return a.seq < b.seq
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(*mergeRecord)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	res := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return res
+}
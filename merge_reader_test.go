@@ -0,0 +1,177 @@
+package blog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// mergeTestLog keeps records with times replaced by the one set before logging them.
+type mergeTestLog struct {
+	bytes.Buffer
+	at time.Time
+}
+
+func (l *mergeTestLog) Write(p []byte) (int, error) {
+	record := bytes.Clone(p)
+	_, size := binary.Uvarint(record[5:])
+	binary.LittleEndian.PutUint64(record[5+size+2:], uint64(l.at.UnixNano()))
+	binary.LittleEndian.PutUint32(record[1:5], crc32.Checksum(record[5+size:], crc32.MakeTable(crc32.Castagnoli)))
+	return l.Buffer.Write(record)
+}
+
+func (l *mergeTestLog) Sync() error {
+	return nil
+}
+
+// newMergeTestLog makes a log of records with messages logged at given offsets of seconds.
+func newMergeTestLog(t *testing.T, base time.Time, records ...any) *mergeTestLog {
+	log := &mergeTestLog{}
+	logger, err := NewLogger(log)
+	assert.NoError(t, err)
+
+	for i := 0; i < len(records); i += 2 {
+		log.at = base.Add(time.Duration(records[i+1].(int)) * time.Second)
+		logger.Info(context.Background(), records[i].(string))
+	}
+
+	return log
+}
+
+func TestMergeReader(t *testing.T) {
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	api := newMergeTestLog(t, base, "a1", 0, "a2", 3, "a3", 4, "a4", 2)
+	worker := newMergeTestLog(t, base, "w1", 1, "w2", 2, "w3", 5)
+
+	merge := func(window time.Duration) []string {
+		r := NewMergeReader(
+			MergeSource{Alias: "api", Reader: bytes.NewReader(api.Bytes())},
+			MergeSource{Alias: "worker", Reader: bytes.NewReader(worker.Bytes())},
+			MergeSource{Alias: "empty", Reader: strings.NewReader("")},
+		).WithReorderWindow(window)
+
+		var res []string
+		var buf bytes.Buffer
+		w := NewPrettyWriter(&buf).WithTimeUTC().WithSources("api", "worker")
+		for {
+			record, source, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			assert.NoError(t, err)
+
+			buf.Reset()
+			_, err = w.WriteSource(source, record)
+			assert.NoError(t, err)
+			res = append(res, strings.TrimSpace(buf.String()))
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{
+		"2026-10-19 12:00:00.000 api    INFO  a1  {}",
+		"2026-10-19 12:00:01.000 worker INFO  w1  {}",
+		"2026-10-19 12:00:02.000 api    INFO  a4  {}",
+		"2026-10-19 12:00:02.000 worker INFO  w2  {}",
+		"2026-10-19 12:00:03.000 api    INFO  a2  {}",
+		"2026-10-19 12:00:04.000 api    INFO  a3  {}",
+		"2026-10-19 12:00:05.000 worker INFO  w3  {}",
+	}, merge(2*time.Second))
+
+	// Without the window the late record is returned out of order.
+	assert.Equal(t, []string{
+		"2026-10-19 12:00:00.000 api    INFO  a1  {}",
+		"2026-10-19 12:00:01.000 worker INFO  w1  {}",
+		"2026-10-19 12:00:02.000 worker INFO  w2  {}",
+		"2026-10-19 12:00:03.000 api    INFO  a2  {}",
+		"2026-10-19 12:00:02.000 api    INFO  a4  {}",
+		"2026-10-19 12:00:04.000 api    INFO  a3  {}",
+		"2026-10-19 12:00:05.000 worker INFO  w3  {}",
+	}, merge(0))
+}
+
+func TestOpenMergeReader(t *testing.T) {
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "api.blog")
+	assert.NoError(t, os.WriteFile(path, newMergeTestLog(t, base, "hello", 0).Bytes(), 0o644))
+
+	_, err := OpenMergeReader(path, path+".missing")
+	assert.Error(t, err)
+
+	r, err := OpenMergeReader(path)
+	assert.NoError(t, err)
+	_, source, err := r.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "api", source)
+	_, _, err = r.Next()
+	assert.IsError(t, err, io.EOF)
+	assert.NoError(t, r.Close())
+}
+
+func TestOpenMergeReaderSameNames(t *testing.T) {
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	var paths []string
+	for i, name := range []string{"api", "worker"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+		path := filepath.Join(dir, name, "app.log")
+		assert.NoError(t, os.WriteFile(path, newMergeTestLog(t, base, name, i).Bytes(), 0o644))
+		paths = append(paths, path)
+	}
+
+	r, err := OpenMergeReader(paths...)
+	assert.NoError(t, err)
+	var sources []string
+	for {
+		_, source, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		sources = append(sources, source)
+	}
+	assert.Equal(t, []string{"api/app", "worker/app"}, sources)
+	assert.NoError(t, r.Close())
+}
+
+func TestMergeAliases(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"api", "a/app", "b/app", "logs/db", "logs/db#2", "cache"},
+		mergeAliases([]string{"api.log", "a/app.log", "b/app.log", "x/logs/db.log", "y/logs/db.log", "cache"}),
+	)
+}
+
+func TestMergeReaderBroken(t *testing.T) {
+	r := NewMergeReader(MergeSource{Alias: "broken", Reader: strings.NewReader("garbage")})
+	_, source, err := r.Next()
+	assert.Error(t, err)
+	assert.Equal(t, "broken", source)
+	assert.Contains(t, err.Error(), "read broken")
+}
+
+func TestPrettyWriterSources(t *testing.T) {
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	log := newMergeTestLog(t, base, "hello", 0)
+
+	var buf bytes.Buffer
+	w := NewPrettyWriter(&buf).WithTheme(ThemeDark(), ColorDepth256).WithSources("db", "api")
+	_, err := w.WriteSource("api", log.Bytes())
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "\033[38;5;141mapi\033[0m ")
+
+	buf.Reset()
+	_, err = w.WriteSource("cache", log.Bytes())
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "\033[38;5;179mcache\033[0m ")
+}
@@ -0,0 +1,70 @@
+package blog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// recordMaxSize limits sizes of records read, larger ones are treated as broken data.
+const recordMaxSize = 64 << 20
+
+// RecordReader reads records written by the [Logger] from a stream, like a log file.
+type RecordReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewRecordReader creates a [RecordReader].
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next returns the next record. The record is only valid until the next call.
+// Returns [io.EOF] when there are no more records.
+func (r *RecordReader) Next() ([]byte, error) {
+	marker, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if marker != 0xFF {
+		return nil, core.NewErrorf("record does not start with 0xFF, got 0x%02x", marker)
+	}
+
+	r.buf = append(r.buf[:0], marker, 0, 0, 0, 0)
+	if _, err := io.ReadFull(r.r, r.buf[1:5]); err != nil {
+		return nil, core.WrapError(unexpectedEOF(err), "read record checksum")
+	}
+
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, core.WrapError(unexpectedEOF(err), "read record length")
+	}
+	if length > recordMaxSize {
+		return nil, core.NewErrorf("record length %d is too large", length)
+	}
+	r.buf = binary.AppendUvarint(r.buf, length)
+
+	start := len(r.buf)
+	r.buf = slices.Grow(r.buf, int(length))[:start+int(length)]
+	if _, err := io.ReadFull(r.r, r.buf[start:]); err != nil {
+		return nil, core.WrapError(unexpectedEOF(err), "read record")
+	}
+
+	return r.buf, nil
+}
+
+// unexpectedEOF turns EOF within a record into [io.ErrUnexpectedEOF].
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,32 @@
+package blog
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and logged with [LogPanic].
+func Go(ctx context.Context, log *Logger, fn func(ctx context.Context)) {
+	core.Go(ctx, log, fn)
+}
+
+// Recover recovers a panic and logs it with [LogPanic]. It must be deferred directly:
+//
+//	defer blog.Recover(ctx, logger)
+func Recover(ctx context.Context, log *Logger) {
+	if r := recover(); r != nil {
+		core.LogRecovered(ctx, log, r)
+	}
+}
+
+// RecoverHandler an alias for [core.RecoverHandler].
+type RecoverHandler = core.RecoverHandler
+
+// NewRecoverHandler wraps the handler to recover its panics, log them with the request info
+// and respond with 500 Internal Server Error. Use [RecoverHandler.WithRepanic] to pass panics
+// further after they are logged.
+func NewRecoverHandler(log *Logger, next http.Handler) *RecoverHandler {
+	return core.NewRecoverHandler(log, next)
+}
@@ -0,0 +1,162 @@
+package blog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+type recoverTestPanic struct {
+	Code    int
+	Reason  string
+	Details map[string]any
+	hidden  bool
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(NewPrettyWriter(&buf))
+	assert.NoError(t, err)
+
+	func() {
+		defer Recover(context.Background(), logger)
+		panic(recoverTestPanic{
+			Code:    42,
+			Reason:  "broken",
+			Details: map[string]any{"attempt": 3},
+		})
+	}()
+
+	out := buf.String()
+	assert.Contains(t, out, "PANIC")
+	assert.Contains(t, out, `"Code": 42`)
+	assert.Contains(t, out, `"Reason": "broken"`)
+	assert.Contains(t, out, `"attempt": 3`)
+	assert.NotContains(t, out, "hidden")
+	assert.Contains(t, out, "TestRecover")
+}
+
+// recoverTestWriter notifies when the first record is written.
+type recoverTestWriter struct {
+	w       io.Writer
+	written chan struct{}
+	once    sync.Once
+}
+
+func (w *recoverTestWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.once.Do(func() {
+		close(w.written)
+	})
+	return n, err
+}
+
+func TestGo(t *testing.T) {
+	var buf bytes.Buffer
+	w := &recoverTestWriter{
+		w:       NewPrettyWriter(&buf),
+		written: make(chan struct{}),
+	}
+	logger, err := NewLogger(w)
+	assert.NoError(t, err)
+
+	Go(context.Background(), logger, func(ctx context.Context) {
+		panic("goroutine failed")
+	})
+
+	select {
+	case <-w.written:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the panic was not logged")
+	}
+	assert.Contains(t, buf.String(), `"recovered": "goroutine failed"`)
+}
+
+func TestRecoverHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(NewPrettyWriter(&buf))
+	assert.NoError(t, err)
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	})
+
+	rec := httptest.NewRecorder()
+	NewRecoverHandler(logger, panicking).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts/42", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, buf.String(), `"method": "GET"`)
+	assert.Contains(t, buf.String(), `"url": "/accounts/42"`)
+	assert.Contains(t, buf.String(), `"recovered": "handler failed"`)
+
+	buf.Reset()
+	handler := NewRecoverHandler(logger, panicking)
+	assert.Panics(t, func() {
+		handler.WithRepanic().ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, "/", nil),
+		)
+	})
+	assert.True(t, strings.Contains(buf.String(), "handler failed"))
+
+	// The original handler does not repanic.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+// recoverTestLocation matches records located in this file.
+var recoverTestLocation = regexp.MustCompile(`location=\S+/recover_test\.go:\d+ `)
+
+func TestRecoverLocation(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(NewLogfmtWriter(&buf), OptionLogLocations())
+	assert.NoError(t, err)
+
+	func() {
+		defer Recover(context.Background(), logger)
+		panic("boom")
+	}()
+	assert.True(t, recoverTestLocation.MatchString(buf.String()), buf.String())
+
+	buf.Reset()
+	w := &recoverTestWriter{
+		w:       &buf,
+		written: make(chan struct{}),
+	}
+	logger, err = NewLogger(NewLogfmtWriter(w), OptionLogLocations())
+	assert.NoError(t, err)
+	Go(context.Background(), logger, func(ctx context.Context) {
+		panic("goroutine failed")
+	})
+	select {
+	case <-w.written:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the panic was not logged")
+	}
+	assert.True(t, recoverTestLocation.MatchString(buf.String()), buf.String())
+}
+
+func TestRecoverTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(NewLogfmtWriter(&buf))
+	assert.NoError(t, err)
+
+	items := make([]int, 100)
+	func() {
+		defer Recover(context.Background(), logger)
+		panic(map[string]any{"items": items})
+	}()
+	out := buf.String()
+	assert.Contains(t, out, "recovered.items.31=0")
+	assert.NotContains(t, out, "recovered.items.32=")
+	assert.Contains(t, out, "recovered.items.@truncated=68")
+}
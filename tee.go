@@ -0,0 +1,84 @@
+package blog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// TeeSink is a destination of the [Tee].
+type TeeSink struct {
+	w    io.Writer
+	from core.LoggingLevel
+}
+
+// Sink creates a destination of the [Tee] getting records with the given level and further.
+// Writers define formats of records, like the [PrettyWriter] does. They must be safe for
+// concurrent use, wrap them with [NewSyncWriter] otherwise.
+func Sink(w io.Writer, from core.LoggingLevel) TeeSink {
+	return TeeSink{
+		w:    w,
+		from: from,
+	}
+}
+
+// Tee writes records into several sinks, each of them only gets records of its levels.
+// Levels are read from record headers, records are not decoded for this.
+//
+// Sinks are isolated from failures of each other: a sink failing to write a record does not
+// prevent others from getting it. They are not isolated from slowness though: records are
+// written into sinks one after another in the logging goroutine, so a sink blocking on writes,
+// like a network connection with a stalled peer, holds back the rest of sinks and logging
+// itself. Put such a sink behind a buffer with its own goroutine and a policy for an overflow.
+type Tee struct {
+	sinks []TeeSink
+}
+
+// NewTee creates a [Tee] writing into the given sinks.
+//
+//	logger, err := blog.NewLogger(blog.NewTee(
+//	    blog.Sink(file, blog.LevelTrace),
+//	    blog.Sink(blog.NewPrettyWriter(os.Stderr), blog.LevelWarning),
+//	))
+func NewTee(sinks ...TeeSink) *Tee {
+	return &Tee{
+		sinks: sinks,
+	}
+}
+
+// Write writes the record into sinks accepting its level, one after another. Data that does not
+// look like a record go to every sink. Errors of failed sinks are joined, the data is considered
+// written anyway.
+func (t *Tee) Write(p []byte) (int, error) {
+	level, ok := core.RecordLevel(p)
+
+	var errs []error
+	for i, s := range t.sinks {
+		if ok && level < s.from {
+			continue
+		}
+		if _, err := s.w.Write(p); err != nil {
+			errs = append(errs, fmt.Errorf("sink #%d: %w", i, err))
+		}
+	}
+
+	return len(p), errors.Join(errs...)
+}
+
+// Sync syncs sinks having Sync() error method, like [os.File] does.
+func (t *Tee) Sync() error {
+	var errs []error
+	for i, s := range t.sinks {
+		ws, ok := s.w.(interface{ Sync() error })
+		if !ok {
+			continue
+		}
+		if err := ws.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("sink #%d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package blog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+type teeTestFailingWriter struct {
+	writes int
+}
+
+func (w *teeTestFailingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk is full")
+}
+
+func (w *teeTestFailingWriter) Sync() error {
+	return errors.New("disk is gone")
+}
+
+func TestTee(t *testing.T) {
+	var raw, warnings, infos bytes.Buffer
+	failing := &teeTestFailingWriter{}
+	tee := NewTee(
+		Sink(failing, LevelTrace),
+		Sink(&raw, LevelTrace),
+		Sink(NewPrettyWriter(&warnings), LevelWarning),
+		Sink(NewPrettyWriter(&infos), LevelInfo),
+	)
+	logger, err := NewLogger(tee)
+	assert.NoError(t, err)
+
+	logger.Debug(context.Background(), "debug record")
+	logger.Info(context.Background(), "info record")
+	logger.Warn(context.Background(), "warn record")
+
+	assert.Equal(t, 3, failing.writes)
+
+	var levels []core.LoggingLevel
+	for rest := raw.Bytes(); len(rest) > 0; {
+		level, ok := core.RecordLevel(rest)
+		assert.True(t, ok)
+		levels = append(levels, level)
+
+		length, size := binary.Uvarint(rest[5:])
+		rest = rest[5+size+int(length):]
+	}
+	assert.Equal(t, []core.LoggingLevel{LevelDebug, LevelInfo, LevelWarning}, levels)
+
+	assert.NotContains(t, warnings.String(), "info record")
+	assert.Contains(t, warnings.String(), "warn record")
+	assert.NotContains(t, infos.String(), "debug record")
+	assert.Contains(t, infos.String(), "info record")
+	assert.Contains(t, infos.String(), "warn record")
+
+	err = tee.Sync()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sink #0: disk is gone")
+}
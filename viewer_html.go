@@ -0,0 +1,401 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// HTMLWriter renders records into a single self-contained HTML page, meant to share logs of incidents.
+// Records are rows colored by their levels with anchors to link them, groups and error stages are
+// collapsible trees, and the page has a filter box to show only records containing the text.
+//
+// Records are rendered as they come, [HTMLWriter.Close] must be called to finish the page.
+type HTMLWriter struct {
+	lock sync.Mutex
+
+	w       io.Writer
+	view    *packedDeconstruct
+	buf     []byte
+	title   string
+	count   int
+	started bool
+
+	value []byte   // Scratch buffer of a value text.
+	bools []bool   // Scratch buffer of unpacked bool slices.
+	strs  []string // Scratch buffer of unpacked string slices.
+}
+
+// NewHTMLWriter creates a [HTMLWriter].
+func NewHTMLWriter(w io.Writer) *HTMLWriter {
+	return &HTMLWriter{
+		w:     w,
+		view:  newPackedDeconstruct(),
+		title: "Log",
+	}
+}
+
+// WithTitle sets a title of the page.
+func (h *HTMLWriter) WithTitle(title string) *HTMLWriter {
+	h.title = title
+	return h
+}
+
+// RenderHTML renders records read from src into the HTML page written into dst.
+func RenderHTML(dst io.Writer, src io.Reader, title string) error {
+	h := NewHTMLWriter(dst).WithTitle(title)
+	r := NewRecordReader(src)
+	for {
+		record, err := r.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return core.WrapError(err, "read record")
+		}
+
+		if _, err := h.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return h.Close()
+}
+
+func (h *HTMLWriter) Write(p []byte) (int, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.view.reset()
+	h.buf = h.buf[:0]
+	if err := core.ProcessRecord(p, h.view); err != nil {
+		return 0, core.WrapError(err, "process record")
+	}
+
+	if !h.started {
+		h.formatHead()
+		h.started = true
+	}
+	h.formatRecord()
+	h.count++
+
+	if _, err := h.w.Write(h.buf); err != nil {
+		return 0, core.WrapError(err, "write html")
+	}
+
+	return len(p), nil
+}
+
+// Close finishes the page. It does not close the underlying writer.
+func (h *HTMLWriter) Close() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.buf = h.buf[:0]
+	if !h.started {
+		h.formatHead()
+		h.started = true
+	}
+	h.buf = append(h.buf, htmlPageTail...)
+
+	if _, err := h.w.Write(h.buf); err != nil {
+		return core.WrapError(err, "write html")
+	}
+
+	return nil
+}
+
+func (h *HTMLWriter) formatHead() {
+	head, tail, _ := bytes.Cut([]byte(htmlPageHead), []byte("{title}"))
+	h.buf = append(h.buf, head...)
+	h.buf = appendHTMLEscaped(h.buf, h.title)
+	h.buf = append(h.buf, tail...)
+}
+
+func (h *HTMLWriter) formatRecord() {
+	v := h.view
+	id := strconv.Itoa(h.count + 1)
+
+	h.buf = append(h.buf, `<div class="record `...)
+	h.buf = append(h.buf, levelName(v.level)...)
+	h.buf = append(h.buf, `" id="r`...)
+	h.buf = append(h.buf, id...)
+	h.buf = append(h.buf, `"><div class="head"><a class="anchor" href="#r`...)
+	h.buf = append(h.buf, id...)
+	h.buf = append(h.buf, `">#`...)
+	h.buf = append(h.buf, id...)
+	h.buf = append(h.buf, `</a> <span class="time">`...)
+	h.buf = v.time.AppendFormat(h.buf, "2006-01-02T15:04:05.000Z07:00")
+	h.buf = append(h.buf, `</span> <span class="level">`...)
+	h.buf = append(h.buf, v.level.String()...)
+	h.buf = append(h.buf, `</span> `...)
+	if v.loc.IsValid() {
+		h.buf = append(h.buf, `<span class="location">`...)
+		h.buf = appendHTMLEscaped(h.buf, v.loc.Filename)
+		h.buf = append(h.buf, ':')
+		h.buf = strconv.AppendInt(h.buf, int64(v.loc.Line), 10)
+		h.buf = append(h.buf, `</span> `...)
+	}
+
+	stacktrace := v.ctx.stacktrace.text
+	if v.level == core.LoggingLevelPanic && isGzipped(v.msg) {
+		// Legacy panic records with a gzipped stack trace as a message.
+		stacktrace = gunzipStacktrace(v.msg)
+	} else {
+		h.buf = append(h.buf, `<span class="message">`...)
+		h.buf = appendHTMLEscaped(h.buf, unsafe.String(unsafe.SliceData(v.msg), len(v.msg)))
+		h.buf = append(h.buf, `</span>`...)
+	}
+	h.buf = append(h.buf, `</div>`...)
+
+	if v.tree.clen > 0 {
+		h.buf = append(h.buf, `<ul class="tree">`...)
+		h.walk(0)
+		h.buf = append(h.buf, `</ul>`...)
+	}
+	if len(stacktrace) > 0 {
+		h.buf = append(h.buf, `<pre class="stack">`...)
+		h.buf = appendHTMLEscaped(h.buf, unsafe.String(unsafe.SliceData(stacktrace), len(stacktrace)))
+		h.buf = append(h.buf, `</pre>`...)
+	}
+	h.buf = append(h.buf, "</div>\n"...)
+}
+
+// walk renders nodes of the same level starting from the one at the pos.
+func (h *HTMLWriter) walk(pos int) {
+	t := h.view.tree
+	for {
+		node := (*prettyViewNode)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.ctrl)), pos))
+		key := t.unpackKey(node)
+
+		class := "key"
+		if slices.Contains(h.view.ctx.errors, pos) {
+			class = "key err-key"
+		}
+
+		switch {
+		case node.kind&0x1F != prettyViewKindRoot:
+			h.buf = append(h.buf, `<li><span class="`...)
+			h.buf = append(h.buf, class...)
+			h.buf = append(h.buf, `">`...)
+			h.buf = appendHTMLEscaped(h.buf, key)
+			h.buf = append(h.buf, `</span>: <span class="value">`...)
+			h.value = h.appendValue(h.value[:0], node)
+			h.buf = appendHTMLEscaped(h.buf, unsafe.String(unsafe.SliceData(h.value), len(h.value)))
+			h.buf = append(h.buf, `</span></li>`...)
+		case node.misc == math.MaxUint32:
+			h.buf = append(h.buf, `<li><span class="`...)
+			h.buf = append(h.buf, class...)
+			h.buf = append(h.buf, `">`...)
+			h.buf = appendHTMLEscaped(h.buf, key)
+			h.buf = append(h.buf, `</span>: {}</li>`...)
+		default:
+			h.buf = append(h.buf, `<li><details open><summary class="`...)
+			h.buf = append(h.buf, class...)
+			h.buf = append(h.buf, `">`...)
+			h.buf = appendHTMLEscaped(h.buf, key)
+			h.buf = append(h.buf, `</summary><ul>`...)
+			h.walk(int(node.misc))
+			h.buf = append(h.buf, `</ul></details></li>`...)
+		}
+
+		if node.next == 0 {
+			return
+		}
+		pos = int(node.next)
+	}
+}
+
+// appendValue appends the text of the leaf node value the same way the tree of the [PrettyWriter] shows it,
+// except slices which are always inline.
+func (h *HTMLWriter) appendValue(buf []byte, node *prettyViewNode) []byte {
+	t := h.view.tree
+	off := node.kind >> 32
+	data := unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)
+
+	switch node.kind & 0x1F {
+	case prettyViewKindValueBool:
+		return strconv.AppendBool(buf, node.kind>>8 != 0)
+	case prettyViewKindValueTime:
+		return time.Unix(0, int64(unpackFullNum(node.kind, node.misc))).AppendFormat(buf, time.RFC3339Nano)
+	case prettyViewKindValueDuration:
+		return append(buf, time.Duration(unpackFullNum(node.kind, node.misc)).String()...)
+	case prettyViewKindValueInt:
+		return strconv.AppendInt(buf, int64(unpackFullNum(node.kind, node.misc)), 10)
+	case prettyViewKindValueUint:
+		return strconv.AppendUint(buf, unpackFullNum(node.kind, node.misc), 10)
+	case prettyViewKindValueFloat:
+		return strconv.AppendFloat(buf, math.Float64frombits(unpackFullNum(node.kind, node.misc)), 'g', -1, 64)
+	case prettyViewKindValueString:
+		return append(buf, unsafe.Slice((*byte)(data), node.misc)...)
+	case prettyViewKindValueStringShort:
+		var shortPlace uint64
+		var longPlace [16]byte
+		return append(buf, unpackShortStringValue(node, &shortPlace, longPlace)...)
+	case prettyViewKindValueByteSlice:
+		buf = append(buf, "base64."...)
+		return base64.RawStdEncoding.AppendEncode(buf, unsafe.Slice((*byte)(data), node.misc))
+	case prettyViewKindValueByteSliceShort:
+		var shortPlace uint64
+		var longPlace [16]byte
+		buf = append(buf, "base64."...)
+		return base64.RawStdEncoding.AppendEncode(buf, unpackShortStringValue(node, &shortPlace, longPlace))
+	case prettyViewKindValueBoolSlice:
+		h.bools = unpackBools(h.bools[:0], node, t)
+		return appendInlineSlice(buf, h.bools, strconv.AppendBool)
+	case prettyViewKindValueBoolSliceShort:
+		h.bools = unpackShortBools(h.bools[:0], node)
+		return appendInlineSlice(buf, h.bools, strconv.AppendBool)
+	case prettyViewKindValueIntSlice, prettyViewKindValueInt64Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*int64)(data), node.misc), appendIntItem)
+	case prettyViewKindValueInt8Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*int8)(data), node.misc), appendIntItem)
+	case prettyViewKindValueInt16Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*int16)(data), node.misc), appendIntItem)
+	case prettyViewKindValueInt32Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*int32)(data), node.misc), appendIntItem)
+	case prettyViewKindValueUintSlice, prettyViewKindValueUint64Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*uint64)(data), node.misc), appendUintItem)
+	case prettyViewKindValueUint8Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*uint8)(data), node.misc), appendUintItem)
+	case prettyViewKindValueUint16Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*uint16)(data), node.misc), appendUintItem)
+	case prettyViewKindValueUint32Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*uint32)(data), node.misc), appendUintItem)
+	case prettyViewKindValueFloat32Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*float32)(data), node.misc), func(buf []byte, v float32) []byte {
+			return strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+		})
+	case prettyViewKindValueFloat64Slice:
+		return appendInlineSlice(buf, unsafe.Slice((*float64)(data), node.misc), func(buf []byte, v float64) []byte {
+			return strconv.AppendFloat(buf, v, 'g', -1, 64)
+		})
+	case prettyViewKindValueStringSlice:
+		h.strs = unpackStrings(h.strs[:0], node, t)
+		return appendInlineSlice(buf, h.strs, strconv.AppendQuote)
+	case prettyViewKindValueSecret:
+		return append(buf, redactedMarker...)
+	default:
+		return strconv.AppendQuote(buf, (node.kind & 0x1F).String())
+	}
+}
+
+func appendInlineSlice[T any](buf []byte, src []T, appendItem func([]byte, T) []byte) []byte {
+	buf = append(buf, '[')
+	for i, v := range src {
+		if i > 0 {
+			buf = append(buf, ',', ' ')
+		}
+		buf = appendItem(buf, v)
+	}
+	return append(buf, ']')
+}
+
+func appendIntItem[T int8 | int16 | int32 | int64](buf []byte, v T) []byte {
+	return strconv.AppendInt(buf, int64(v), 10)
+}
+
+func appendUintItem[T uint8 | uint16 | uint32 | uint64](buf []byte, v T) []byte {
+	return strconv.AppendUint(buf, uint64(v), 10)
+}
+
+func levelName(level core.LoggingLevel) string {
+	switch level {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warn"
+	case LevelError:
+		return "error"
+	case core.LoggingLevelPanic:
+		return "panic"
+	case core.LoggingLevelFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// appendHTMLEscaped appends the text escaping characters having special meaning in HTML.
+func appendHTMLEscaped(buf []byte, text string) []byte {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '<':
+			buf = append(buf, "&lt;"...)
+		case '>':
+			buf = append(buf, "&gt;"...)
+		case '&':
+			buf = append(buf, "&amp;"...)
+		case '"':
+			buf = append(buf, "&#34;"...)
+		case '\'':
+			buf = append(buf, "&#39;"...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+
+	return buf
+}
+
+const htmlPageHead = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{title}</title>
+<style>
+body { margin: 0; background: #fdfdfd; color: #202020; font: 13px/1.5 ui-monospace, Menlo, Consolas, monospace; }
+header { position: sticky; top: 0; padding: 8px 12px; background: #f0f0f0; border-bottom: 1px solid #d0d0d0; }
+header input { width: 40em; max-width: 100%; font: inherit; padding: 2px 6px; }
+.record { padding: 2px 12px; border-left: 4px solid transparent; border-bottom: 1px solid #eeeeee; }
+.record:target { background: #fff6cc; }
+.anchor { color: #a0a0a0; text-decoration: none; }
+.time, .location { color: #808080; }
+.level { font-weight: bold; }
+.message { font-weight: bold; }
+.trace { border-left-color: #c0c0c0; } .trace .level { color: #a0a0a0; }
+.debug { border-left-color: #6699cc; } .debug .level { color: #336699; }
+.info { border-left-color: #66aa66; } .info .level { color: #338833; }
+.warn { background: #fffaf0; border-left-color: #ee9900; } .warn .level { color: #cc7700; }
+.error, .panic, .fatal { background: #fff3f3; border-left-color: #dd3333; }
+.error .level, .panic .level, .fatal .level { color: #cc0000; }
+ul { margin: 0; padding-left: 1.5em; list-style: none; }
+ul.tree { padding-left: 2.5em; }
+summary { cursor: pointer; }
+.key { color: #6a4c9c; }
+.key.err-key { color: #cc0000; font-weight: bold; }
+.value { white-space: pre-wrap; word-break: break-all; }
+.stack { margin: 4px 0 4px 2.5em; color: #606060; }
+</style>
+</head>
+<body>
+<header><input id="filter" type="search" placeholder="Show records containing…" autofocus></header>
+<main>
+`
+
+const htmlPageTail = `</main>
+<script>
+const filter = document.getElementById("filter");
+filter.addEventListener("input", () => {
+  const text = filter.value.toLowerCase();
+  for (const record of document.querySelectorAll(".record")) {
+    record.hidden = text !== "" && !record.textContent.toLowerCase().includes(text);
+  }
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,103 @@
+package blog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/sirkon/blog/beer"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestRenderHTML(t *testing.T) {
+	var raw bytes.Buffer
+	logger, err := NewLogger(&raw)
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "request <served>", Group("request", Str("path", "/a&b"), Ints("ids", []int{1, 2})))
+	logger.Error(context.Background(), "failed", Err(beer.Wrap(beer.New("disk is full").Int("free", 0), "save")))
+
+	var page strings.Builder
+	assert.NoError(t, RenderHTML(&page, &raw, "Incident <42>"))
+	html := page.String()
+
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.True(t, strings.HasSuffix(html, "</html>\n"))
+	assert.Contains(t, html, "<title>Incident &lt;42&gt;</title>")
+	assert.Contains(t, html, `<div class="record info" id="r1">`)
+	assert.Contains(t, html, `<a class="anchor" href="#r2">#2</a>`)
+	assert.Contains(t, html, `<span class="message">request &lt;served&gt;</span>`)
+	assert.Contains(t, html, `<summary class="key">request</summary>`)
+	assert.Contains(t, html, `<span class="key">path</span>: <span class="value">/a&amp;b</span>`)
+	assert.Contains(t, html, `<span class="value">[1, 2]</span>`)
+	assert.Contains(t, html, `<div class="record error" id="r2">`)
+	assert.Contains(t, html, `<span class="key err-key">@text</span>: <span class="value">save: disk is full</span>`)
+	assert.Contains(t, html, `<span class="key">free</span>: <span class="value">0</span>`)
+}
+
+func TestRenderHTMLLegacyPanic(t *testing.T) {
+	var page strings.Builder
+	assert.NoError(t, RenderHTML(&page, bytes.NewReader(legacyPanicRecord(prettyTestPanicStacktrace)), "Panic"))
+	html := page.String()
+
+	assert.Contains(t, html, `<div class="record panic" id="r1">`)
+	assert.Contains(t, html, "<pre class=\"stack\">goroutine 8 [running]:\n")
+	assert.Contains(t, html, "main.handle({0x0, 0x0, 0x0})\n\t/src/main.go:24 +0x66\n")
+	assert.NotContains(t, html, `<span class="message">`)
+}
+
+// legacyPanicRecord makes a panic record of older versions, with the gzipped stack trace as a message.
+func legacyPanicRecord(stacktrace string) []byte {
+	var msg bytes.Buffer
+	zw := gzip.NewWriter(&msg)
+	_, _ = zw.Write([]byte(stacktrace))
+	_ = zw.Close()
+
+	body := binary.LittleEndian.AppendUint16(nil, core.Version)
+	body = binary.LittleEndian.AppendUint64(body, uint64(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC).UnixNano()))
+	body = append(body, byte(core.LoggingLevelPanic), 0)
+	body = binary.AppendUvarint(body, uint64(msg.Len()))
+	body = append(body, msg.Bytes()...)
+
+	record := binary.LittleEndian.AppendUint32([]byte{0xFF}, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+	record = binary.AppendUvarint(record, uint64(len(body)))
+	return append(record, body...)
+}
+
+func TestRecordReader(t *testing.T) {
+	var raw bytes.Buffer
+	logger, err := NewLogger(&raw)
+	assert.NoError(t, err)
+	logger.Info(context.Background(), "first")
+	logger.Info(context.Background(), "second")
+
+	data := raw.Bytes()
+	r := NewRecordReader(bytes.NewReader(data[:len(data)-1]))
+	record, err := r.Next()
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	_, err = NewPrettyWriter(&out).Write(record)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "first")
+
+	_, err = r.Next()
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), err)
+
+	r = NewRecordReader(bytes.NewReader(data))
+	for range 2 {
+		_, err = r.Next()
+		assert.NoError(t, err)
+	}
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
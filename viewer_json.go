@@ -0,0 +1,557 @@
+package blog
+
+import (
+	"encoding/base64"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// JSONWriter renders records as JSON lines, an object per record, for log shippers and tools like jq:
+//
+//	{"time":"2006-01-02T15:04:05.000+03:00","level":"error","location":"main.go:42","msg":"save failed","user":{"id":13},"err":{"stages":[{"stage":"new","msg":"disk is full","context":{"free":0}},{"stage":"wrap","msg":"save"}],"text":"save: disk is full"}}
+//
+// Groups become nested objects. Errors are objects with their stages in the order of their happening,
+// the origin of the error goes first, each stage keeps its context in the context object. Remote stages
+// keep stages of their remote errors in the stages array. Stack traces are rendered as a single stack string.
+type JSONWriter struct {
+	lock sync.Mutex
+
+	w   io.Writer
+	buf []byte
+	ctx jsonContext
+
+	timeUTC bool
+	seq     int // Number of the record in the deterministic mode.
+}
+
+// NewJSONWriter creates a [JSONWriter].
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{
+		w: w,
+	}
+}
+
+// WithTimeUTC shows record times in UTC instead of the local time.
+func (j *JSONWriter) WithTimeUTC() *JSONWriter {
+	j.timeUTC = true
+	return j
+}
+
+// WithDeterministic renders records the same way on every run and on every machine, for golden files
+// of tests: record times are replaced with sequence numbers like "seq":1, values of times and durations
+// with "<time>" and "<duration>", absolute paths of locations and stack frames are cut to file names,
+// and offsets of stack frames are omitted.
+func (j *JSONWriter) WithDeterministic() *JSONWriter {
+	j.ctx.determined = true
+	return j
+}
+
+func (j *JSONWriter) Write(p []byte) (int, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.buf = append(j.buf[:0], '{')
+	j.ctx.reset(j.buf)
+	if err := core.ProcessRecord(p, (*jsonRecord)(j)); err != nil {
+		return 0, core.WrapError(err, "process record")
+	}
+	j.buf = append(j.ctx.buf, '}', '\n')
+
+	if _, err := j.w.Write(j.buf); err != nil {
+		return 0, core.WrapError(err, "write json")
+	}
+
+	return len(p), nil
+}
+
+// Sync syncs the underlying writer if it has Sync() error method, like [os.File] does.
+func (j *JSONWriter) Sync() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if ws, ok := j.w.(interface{ Sync() error }); ok {
+		return ws.Sync()
+	}
+
+	return nil
+}
+
+// jsonRecord is a [core.RecordViewer] of the [JSONWriter]. Its methods are called in order,
+// so the header of the record is written right away.
+type jsonRecord JSONWriter
+
+func (r *jsonRecord) Time(t time.Time) {
+	if r.ctx.determined {
+		r.seq++
+		r.ctx.appendKey([]byte("seq"))
+		r.ctx.buf = strconv.AppendInt(r.ctx.buf, int64(r.seq), 10)
+		return
+	}
+	if r.timeUTC {
+		t = t.UTC()
+	}
+	r.ctx.appendKey([]byte("time"))
+	r.ctx.buf = append(r.ctx.buf, '"')
+	r.ctx.buf = t.AppendFormat(r.ctx.buf, "2006-01-02T15:04:05.000Z07:00")
+	r.ctx.buf = append(r.ctx.buf, '"')
+}
+
+func (r *jsonRecord) Level(level core.LoggingLevel) {
+	r.ctx.level = level
+	r.ctx.text([]byte("level"), []byte(levelName(level)))
+}
+
+func (r *jsonRecord) Location(file []byte, line int) {
+	r.ctx.value = r.ctx.appendPath(r.ctx.value[:0], file)
+	r.ctx.value = append(r.ctx.value, ':')
+	r.ctx.value = strconv.AppendInt(r.ctx.value, int64(line), 10)
+	r.ctx.text([]byte("location"), r.ctx.value)
+}
+
+func (r *jsonRecord) Message(msg []byte) {
+	if r.ctx.level == core.LoggingLevelPanic && isGzipped(msg) {
+		// Legacy panic records with a gzipped stack trace as a message.
+		r.ctx.stacktrace.text = append(r.ctx.stacktrace.text, gunzipStacktrace(msg)...)
+		msg = []byte("panic")
+	}
+	r.ctx.text([]byte("msg"), msg)
+}
+
+func (r *jsonRecord) ContextVisitor() core.RecordContextVisitor {
+	return &r.ctx
+}
+
+// jsonContext writes the context of the record into the buffer. Secrets come as strings
+// with [core.RedactedValue].
+type jsonContext struct {
+	buf        []byte
+	level      core.LoggingLevel
+	determined bool
+
+	// first is set when nothing was written into the current object or array yet.
+	first bool
+	// depth is the number of objects and arrays of the context opened.
+	depth int
+
+	// errors counts errors being processed.
+	errors int
+	// stages keeps the error stages being processed, the last one is the current stage.
+	stages []jsonStage
+	// fingerprint is the pending fingerprint of the current error, shown after its text.
+	fingerprint    uint64
+	hasFingerprint bool
+
+	stacktrace prettyStacktrace
+
+	value []byte // Scratch buffer of a value text.
+}
+
+func (c *jsonContext) reset(buf []byte) {
+	c.buf = buf
+	c.first = true
+	c.depth = 0
+	c.errors = 0
+	c.stages = c.stages[:0]
+	c.hasFingerprint = false
+	c.stacktrace.reset()
+}
+
+// separate writes a comma unless this is the first item of the object or array.
+func (c *jsonContext) separate() {
+	if !c.first {
+		c.buf = append(c.buf, ',')
+	}
+	c.first = false
+}
+
+// open starts an object or an array with the given bracket.
+func (c *jsonContext) open(bracket byte) {
+	c.buf = append(c.buf, bracket)
+	c.first = true
+	c.depth++
+}
+
+// close finishes an object or an array with the given bracket.
+func (c *jsonContext) close(bracket byte) {
+	c.buf = append(c.buf, bracket)
+	c.first = false
+	c.depth--
+}
+
+// jsonStage is an error stage being processed.
+type jsonStage struct {
+	depth int  // Depth of the stage object, to tell its parts from nested groups.
+	part  byte // The opening bracket of the part of the stage being written, if any.
+}
+
+// appendKey writes the separator and the key of the member. Members of error stages go
+// into their context objects.
+func (c *jsonContext) appendKey(key []byte) {
+	c.stagePart('{')
+	c.member(key)
+}
+
+// member writes the separator and the key of the member as is.
+func (c *jsonContext) member(key []byte) {
+	c.separate()
+	c.buf = appendJSONString(c.buf, key)
+	c.buf = append(c.buf, ':')
+}
+
+// stagePart opens the part of the current error stage with the given bracket, when it
+// is not opened yet and the context is right in the stage: the context object of the stage
+// for its members, or the stages array for stages of the remote error.
+func (c *jsonContext) stagePart(bracket byte) {
+	if len(c.stages) == 0 {
+		return
+	}
+
+	s := &c.stages[len(c.stages)-1]
+	switch {
+	case s.part == bracket:
+		return
+	case s.part == 0 && c.depth != s.depth:
+		return
+	case s.part != 0 && c.depth != s.depth+1:
+		return
+	}
+
+	c.closeStagePart(s)
+	if bracket == '{' {
+		c.member([]byte("context"))
+	} else {
+		c.member([]byte("stages"))
+	}
+	c.open(bracket)
+	s.part = bracket
+}
+
+// closeStagePart closes the part of the stage being written.
+func (c *jsonContext) closeStagePart(s *jsonStage) {
+	switch s.part {
+	case '{':
+		c.close('}')
+	case '[':
+		c.close(']')
+	}
+	s.part = 0
+}
+
+func (c *jsonContext) text(key []byte, value []byte) {
+	c.appendKey(key)
+	c.buf = appendJSONString(c.buf, value)
+}
+
+// raw writes the value which is valid JSON already.
+func (c *jsonContext) raw(key []byte, value []byte) {
+	c.appendKey(key)
+	c.buf = append(c.buf, value...)
+}
+
+// slice writes the slice rendered into the scratch buffer, keeping the buffer for reuse.
+func (c *jsonContext) slice(key []byte, value []byte) {
+	c.value = value
+	c.raw(key, value)
+}
+
+func (c *jsonContext) Bool(key []byte, value bool) {
+	c.appendKey(key)
+	c.buf = strconv.AppendBool(c.buf, value)
+}
+
+func (c *jsonContext) Time(key []byte, value time.Time) {
+	if c.determined {
+		c.text(key, []byte("<time>"))
+		return
+	}
+	c.text(key, value.AppendFormat(c.value[:0], time.RFC3339Nano))
+}
+
+func (c *jsonContext) Duration(key []byte, value time.Duration) {
+	if c.determined {
+		c.text(key, []byte("<duration>"))
+		return
+	}
+	c.text(key, []byte(value.String()))
+}
+
+func (c *jsonContext) Int(key []byte, value int) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.int(key, value)
+		return
+	}
+	c.Int64(key, int64(value))
+}
+
+func (c *jsonContext) Int8(key []byte, value int8)   { c.Int64(key, int64(value)) }
+func (c *jsonContext) Int16(key []byte, value int16) { c.Int64(key, int64(value)) }
+func (c *jsonContext) Int32(key []byte, value int32) { c.Int64(key, int64(value)) }
+
+func (c *jsonContext) Int64(key []byte, value int64) {
+	c.appendKey(key)
+	c.buf = strconv.AppendInt(c.buf, value, 10)
+}
+
+func (c *jsonContext) Uint(key []byte, value uint)     { c.Uint64(key, uint64(value)) }
+func (c *jsonContext) Uint8(key []byte, value uint8)   { c.Uint64(key, uint64(value)) }
+func (c *jsonContext) Uint16(key []byte, value uint16) { c.Uint64(key, uint64(value)) }
+func (c *jsonContext) Uint32(key []byte, value uint32) { c.Uint64(key, uint64(value)) }
+
+func (c *jsonContext) Uint64(key []byte, value uint64) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.uint(key, value)
+		return
+	}
+	if c.errors > 0 && string(key) == "@fingerprint" {
+		c.fingerprint = value
+		c.hasFingerprint = true
+		return
+	}
+	c.appendKey(key)
+	c.buf = strconv.AppendUint(c.buf, value, 10)
+}
+
+func (c *jsonContext) Float32(key []byte, value float32) {
+	c.appendKey(key)
+	c.buf = appendJSONFloat(c.buf, float64(value), 32)
+}
+
+func (c *jsonContext) Float64(key []byte, value float64) {
+	c.appendKey(key)
+	c.buf = appendJSONFloat(c.buf, value, 64)
+}
+
+func (c *jsonContext) Str(key []byte, value []byte) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.str(key, value)
+		return
+	}
+	c.text(key, value)
+}
+
+func (c *jsonContext) Bytes(key []byte, value []byte) {
+	c.value = append(c.value[:0], '"')
+	c.value = base64.StdEncoding.AppendEncode(c.value, value)
+	c.value = append(c.value, '"')
+	c.raw(key, c.value)
+}
+
+func (c *jsonContext) RawError(key []byte, value []byte) {
+	c.text(key, value)
+}
+
+func (c *jsonContext) BoolSlice(key []byte, seq []bool) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, strconv.AppendBool))
+}
+
+func (c *jsonContext) IntSlice(key []byte, seq []int) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v int) []byte {
+		return strconv.AppendInt(buf, int64(v), 10)
+	}))
+}
+
+func (c *jsonContext) Int8Slice(key []byte, seq []int8) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *jsonContext) Int16Slice(key []byte, seq []int16) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *jsonContext) Int32Slice(key []byte, seq []int32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *jsonContext) Int64Slice(key []byte, seq []int64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *jsonContext) UintSlice(key []byte, seq []uint) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v uint) []byte {
+		return strconv.AppendUint(buf, uint64(v), 10)
+	}))
+}
+
+func (c *jsonContext) Uint8Slice(key []byte, seq []uint8) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *jsonContext) Uint16Slice(key []byte, seq []uint16) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *jsonContext) Uint32Slice(key []byte, seq []uint32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *jsonContext) Uint64Slice(key []byte, seq []uint64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *jsonContext) Float32Slice(key []byte, seq []float32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v float32) []byte {
+		return appendJSONFloat(buf, float64(v), 32)
+	}))
+}
+
+func (c *jsonContext) Float64Slice(key []byte, seq []float64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v float64) []byte {
+		return appendJSONFloat(buf, v, 64)
+	}))
+}
+
+func (c *jsonContext) StrSlice(key []byte, seq [][]byte) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendJSONString))
+}
+
+func (c *jsonContext) EnterGroup(key []byte) {
+	if c.stacktrace.depth > 0 || c.depth == 0 && string(key) == "@stack" {
+		c.stacktrace.enter(key)
+		return
+	}
+	c.appendKey(key)
+	c.open('{')
+}
+
+func (c *jsonContext) LeaveGroup() {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.leave()
+		return
+	}
+	c.close('}')
+}
+
+func (c *jsonContext) EnterError(key []byte) {
+	c.errors++
+	c.appendKey(key)
+	c.open('{')
+	c.member([]byte("stages"))
+	c.open('[')
+}
+
+func (c *jsonContext) EnterErrorStage(state core.ErrorProcessingStage, text []byte) {
+	// Stages of a remote error are kept by its remote stage.
+	c.stagePart('[')
+	c.separate()
+	c.open('{')
+	c.stages = append(c.stages, jsonStage{depth: c.depth})
+
+	switch state {
+	case core.ErrorProcessingStageNew:
+		c.stageText("stage", []byte("new"))
+		c.stageText("msg", text)
+	case core.ErrorProcessingStageWrap:
+		c.stageText("stage", []byte("wrap"))
+		c.stageText("msg", text)
+	case core.ErrorProcessingStageContext:
+		c.stageText("stage", []byte("ctx"))
+	case core.ErrorProcessingStageRemote:
+		c.stageText("stage", []byte("remote"))
+		c.stageText("msg", text)
+	}
+}
+
+// stageText writes the member of the error stage itself.
+func (c *jsonContext) stageText(key string, value []byte) {
+	c.member([]byte(key))
+	c.buf = appendJSONString(c.buf, value)
+}
+
+func (c *jsonContext) ErrorStageLocation(file []byte, line int) {
+	c.closeStagePart(&c.stages[len(c.stages)-1])
+	c.value = c.appendPath(c.value[:0], file)
+	c.value = append(c.value, ':')
+	c.value = strconv.AppendInt(c.value, int64(line), 10)
+	c.stageText("location", c.value)
+}
+
+func (c *jsonContext) LeaveErrorStage() {
+	c.closeStagePart(&c.stages[len(c.stages)-1])
+	c.stages = c.stages[:len(c.stages)-1]
+	c.close('}')
+}
+
+func (c *jsonContext) LeaveError(text []byte) {
+	c.close(']')
+	c.member([]byte("text"))
+	c.buf = appendJSONString(c.buf, text)
+	if c.hasFingerprint {
+		c.member([]byte("fingerprint"))
+		c.buf = appendJSONString(c.buf, strconv.AppendUint(c.value[:0], c.fingerprint, 16))
+		c.hasFingerprint = false
+	}
+	c.close('}')
+	c.errors--
+}
+
+func (c *jsonContext) Finish() {
+	if len(c.stacktrace.text) == 0 {
+		return
+	}
+	if c.determined {
+		c.value = appendDeterministicStacktrace(c.value[:0], c.stacktrace.text)
+		c.text([]byte("stack"), c.value)
+		return
+	}
+	c.text([]byte("stack"), c.stacktrace.text)
+}
+
+// appendPath appends the path of the location, absolute paths are cut to file names in the deterministic mode.
+func (c *jsonContext) appendPath(buf []byte, file []byte) []byte {
+	if c.determined {
+		return appendDeterministicPath(buf, file)
+	}
+
+	return append(buf, file...)
+}
+
+// appendJSONString appends the text as a JSON string. Invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(buf []byte, text []byte) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(text[i:])
+			buf = utf8.AppendRune(buf, r)
+			i += size
+			continue
+		}
+
+		switch {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c == '\n':
+			buf = append(buf, '\\', 'n')
+		case c == '\r':
+			buf = append(buf, '\\', 'r')
+		case c == '\t':
+			buf = append(buf, '\\', 't')
+		case c < ' ' || c == 0x7F:
+			buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		default:
+			buf = append(buf, c)
+		}
+		i++
+	}
+
+	return append(buf, '"')
+}
+
+// appendJSONFloat appends the float, NaN and infinities are not valid JSON numbers and are quoted.
+func appendJSONFloat(buf []byte, value float64, bits int) []byte {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		buf = append(buf, '"')
+		buf = strconv.AppendFloat(buf, value, 'g', -1, bits)
+		return append(buf, '"')
+	}
+
+	return strconv.AppendFloat(buf, value, 'g', -1, bits)
+}
@@ -0,0 +1,141 @@
+package blog
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/sirkon/blog/beer"
+	"github.com/sirkon/blog/internal/core"
+)
+
+func TestJSONWriter(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewJSONWriter(&buf).WithTimeUTC())
+	assert.NoError(t, err)
+
+	logger.Info(
+		context.Background(),
+		"request served",
+		Group("request", Str("path", "/users"), Str("query", `name="a b"`), Ints("ids", []int{1, 2})),
+		Duration("took", 1500*time.Millisecond),
+		Strs("tags", []string{"a", "b"}),
+		Flt64("ratio", math.NaN()),
+		Str("text", "first line\nsecond line\x01"),
+		Secret("token", "hunter2"),
+	)
+	logger.Error(context.Background(), "failed", Err(beer.Wrap(beer.New("disk is full").Int("free", 0), "save")))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 2, len(lines), buf.String())
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+
+	head, ctx, _ := strings.Cut(lines[0], `,"msg":`)
+	assert.True(t, strings.HasPrefix(head, `{"time":"`), head)
+	assert.True(t, strings.HasSuffix(head, `Z","level":"info"`), head)
+	assert.Equal(
+		t,
+		`"request served","request":{"path":"/users","query":"name=\"a b\"","ids":[1,2]},"took":"1.5s","tags":["a","b"],"ratio":"NaN","text":"first line\nsecond line\u0001","token":"`+core.RedactedValue+`"}`,
+		ctx,
+	)
+
+	_, ctx, _ = strings.Cut(lines[1], `,"level":`)
+	assert.Equal(
+		t,
+		`"error","msg":"failed","err":{"stages":[{"stage":"new","msg":"disk is full","context":{"free":0}},{"stage":"wrap","msg":"save"}],"text":"save: disk is full"}}`,
+		ctx,
+	)
+}
+
+func TestJSONWriterDeterministic(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewJSONWriter(&buf).WithDeterministic(), OptionLogLocations())
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "first", Duration("took", time.Second), Time("at", time.Now()))
+	logger.Info(context.Background(), "second")
+	core.LogPanic(context.Background(), logger, []byte(prettyTestPanicStacktrace), core.LogPanicInfo("boom"))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, `{"seq":1,"level":"info","location":"viewer_json_test.go:62","msg":"first","took":"<duration>","at":"<time>"}`, lines[0])
+	assert.Equal(t, `{"seq":2,"level":"info","location":"viewer_json_test.go:63","msg":"second"}`, lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], `{"seq":3,"level":"panic","location":"viewer_json_test.go:64","msg":"panic","recovered":"boom","stack":"goroutine 8 [running]:\n`), lines[2])
+	assert.Contains(t, lines[2], `main.handle\n\tmain.go:24\n`)
+	assert.True(t, json.Valid([]byte(lines[2])), lines[2])
+}
+
+// jsonTestStage is a stage of an error decoded from the output of [JSONWriter].
+type jsonTestStage struct {
+	Stage   string          `json:"stage"`
+	Msg     string          `json:"msg"`
+	Context map[string]any  `json:"context"`
+	Stages  []jsonTestStage `json:"stages"`
+}
+
+// jsonTestRecord is a record decoded from the output of [JSONWriter].
+type jsonTestRecord struct {
+	Err struct {
+		Stages []jsonTestStage `json:"stages"`
+		Text   string          `json:"text"`
+	} `json:"err"`
+}
+
+func decodeJSONTestRecord(t *testing.T, line string) jsonTestRecord {
+	t.Helper()
+
+	var rec jsonTestRecord
+	assert.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+
+	return rec
+}
+
+func TestJSONWriterRemoteError(t *testing.T) {
+	data, err := beer.MarshalBinary(beer.Wrap(beer.New("no funds").Int("account-id", 42), "charge"), "billing")
+	assert.NoError(t, err)
+	remote, err := beer.UnmarshalBinary(data)
+	assert.NoError(t, err)
+
+	var buf strings.Builder
+	logger, err := NewLogger(NewJSONWriter(&buf).WithDeterministic())
+	assert.NoError(t, err)
+	logger.Error(context.Background(), "failed", Err(beer.Wrap(remote, "pay")))
+
+	rec := decodeJSONTestRecord(t, buf.String())
+	assert.Equal(t, []jsonTestStage{
+		{
+			Stage: "remote",
+			Msg:   "billing",
+			Stages: []jsonTestStage{
+				{Stage: "new", Msg: "no funds", Context: map[string]any{"account-id": float64(42)}},
+				{Stage: "wrap", Msg: "charge"},
+			},
+		},
+		{Stage: "wrap", Msg: "pay"},
+	}, rec.Err.Stages)
+	assert.Equal(t, "pay: charge: no funds", rec.Err.Text)
+}
+
+func TestJSONWriterStageKeys(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewJSONWriter(&buf).WithDeterministic())
+	assert.NoError(t, err)
+
+	err = beer.New("disk is full").Str("msg", "context").Str("stage", "context").Str("stages", "context")
+	logger.Error(context.Background(), "failed", Err(err))
+
+	rec := decodeJSONTestRecord(t, buf.String())
+	assert.Equal(t, []jsonTestStage{
+		{
+			Stage:   "new",
+			Msg:     "disk is full",
+			Context: map[string]any{"msg": "context", "stage": "context", "stages": "context"},
+		},
+	}, rec.Err.Stages)
+}
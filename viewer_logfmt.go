@@ -0,0 +1,597 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// LogfmtWriter renders records as single lines of key=value pairs, for CI logs and consumers
+// of journalctl-like output:
+//
+//	time=2006-01-02T15:04:05.000+03:00 level=error location=main.go:42 msg="save failed" user.id=13 err.0.stage=new err.0.msg="disk is full" err.0.free=0 err.1.stage=wrap err.1.msg=save err.text="save: disk is full"
+//
+// Keys of nested groups are joined with dots. Stages of errors are flattened into err.N.* keys
+// in the order of their happening, N=0 is the origin of the error, and the text of the error is err.text.
+// Stages of remote errors are numbered within their remote stage, as err.N.M.* keys. Context keys
+// of stages which look the same as keys of stages themselves get an underscore prepended.
+// Stack traces are rendered as a single stack value with escaped line breaks.
+type LogfmtWriter struct {
+	lock sync.Mutex
+
+	w   io.Writer
+	buf []byte
+	ctx logfmtContext
+
+	timeUTC bool
+	seq     int // Number of the record in the deterministic mode.
+}
+
+// NewLogfmtWriter creates a [LogfmtWriter].
+func NewLogfmtWriter(w io.Writer) *LogfmtWriter {
+	return &LogfmtWriter{
+		w: w,
+	}
+}
+
+// WithTimeUTC shows record times in UTC instead of the local time.
+func (l *LogfmtWriter) WithTimeUTC() *LogfmtWriter {
+	l.timeUTC = true
+	return l
+}
+
+// WithDeterministic renders records the same way on every run and on every machine, for golden files
+// of tests: record times are replaced with sequence numbers like seq=1, values of times and durations
+// with <time> and <duration>, absolute paths of locations and stack frames are cut to file names,
+// and offsets of stack frames are omitted.
+func (l *LogfmtWriter) WithDeterministic() *LogfmtWriter {
+	l.ctx.determined = true
+	return l
+}
+
+func (l *LogfmtWriter) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.buf = l.buf[:0]
+	l.ctx.reset(l.buf)
+	if err := core.ProcessRecord(p, (*logfmtRecord)(l)); err != nil {
+		return 0, core.WrapError(err, "process record")
+	}
+	l.buf = append(l.ctx.buf, '\n')
+
+	if _, err := l.w.Write(l.buf); err != nil {
+		return 0, core.WrapError(err, "write logfmt")
+	}
+
+	return len(p), nil
+}
+
+// Sync syncs the underlying writer if it has Sync() error method, like [os.File] does.
+func (l *LogfmtWriter) Sync() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if ws, ok := l.w.(interface{ Sync() error }); ok {
+		return ws.Sync()
+	}
+
+	return nil
+}
+
+// logfmtRecord is a [core.RecordViewer] of the [LogfmtWriter]. Its methods are called in order,
+// so the header of the record is written right away.
+type logfmtRecord LogfmtWriter
+
+func (r *logfmtRecord) Time(t time.Time) {
+	if r.ctx.determined {
+		r.seq++
+		r.ctx.buf = append(r.ctx.buf, "seq="...)
+		r.ctx.buf = strconv.AppendInt(r.ctx.buf, int64(r.seq), 10)
+		return
+	}
+	if r.timeUTC {
+		t = t.UTC()
+	}
+	r.ctx.buf = append(r.ctx.buf, "time="...)
+	r.ctx.buf = t.AppendFormat(r.ctx.buf, "2006-01-02T15:04:05.000Z07:00")
+}
+
+func (r *logfmtRecord) Level(level core.LoggingLevel) {
+	r.ctx.level = level
+	r.ctx.buf = append(r.ctx.buf, " level="...)
+	r.ctx.buf = append(r.ctx.buf, levelName(level)...)
+}
+
+func (r *logfmtRecord) Location(file []byte, line int) {
+	r.ctx.value = r.ctx.appendPath(r.ctx.value[:0], file)
+	r.ctx.value = append(r.ctx.value, ':')
+	r.ctx.value = strconv.AppendInt(r.ctx.value, int64(line), 10)
+	r.ctx.buf = append(r.ctx.buf, " location="...)
+	r.ctx.buf = appendLogfmtValue(r.ctx.buf, r.ctx.value)
+}
+
+func (r *logfmtRecord) Message(msg []byte) {
+	if r.ctx.level == core.LoggingLevelPanic && isGzipped(msg) {
+		// Legacy panic records with a gzipped stack trace as a message.
+		r.ctx.stacktrace.text = append(r.ctx.stacktrace.text, gunzipStacktrace(msg)...)
+		msg = []byte("panic")
+	}
+	r.ctx.buf = append(r.ctx.buf, " msg="...)
+	r.ctx.buf = appendLogfmtValue(r.ctx.buf, msg)
+}
+
+func (r *logfmtRecord) ContextVisitor() core.RecordContextVisitor {
+	return &r.ctx
+}
+
+// logfmtContext writes the context of the record into the buffer.
+type logfmtContext struct {
+	buf        []byte
+	level      core.LoggingLevel
+	determined bool
+
+	// key is a prefix of keys of the current group, keys keeps lengths of prefixes of outer groups.
+	key  []byte
+	keys []int
+
+	// stages counts stages per nesting level of errors being processed: every error starts
+	// a level, and so does every remote stage for stages of its remote error.
+	stages []int
+	// open keeps the error stages being processed, the last one is the current stage.
+	open []logfmtStage
+	// fingerprint is the pending fingerprint of the current error, shown after its text.
+	fingerprint    uint64
+	hasFingerprint bool
+
+	stacktrace prettyStacktrace
+
+	value []byte // Scratch buffer of a value text.
+}
+
+func (c *logfmtContext) reset(buf []byte) {
+	c.buf = buf
+	c.key = c.key[:0]
+	c.keys = c.keys[:0]
+	c.stages = c.stages[:0]
+	c.open = c.open[:0]
+	c.hasFingerprint = false
+	c.stacktrace.reset()
+}
+
+// push starts a group with the given key.
+func (c *logfmtContext) push(key []byte) {
+	c.keys = append(c.keys, len(c.key))
+	if len(c.key) > 0 {
+		c.key = append(c.key, '.')
+	}
+	c.key = appendLogfmtKey(c.key, key)
+}
+
+func (c *logfmtContext) pop() {
+	c.key = c.key[:c.keys[len(c.keys)-1]]
+	c.keys = c.keys[:len(c.keys)-1]
+}
+
+// logfmtStage is an error stage being processed.
+type logfmtStage struct {
+	keys   int // Length of keys of the stage, to tell its context from nested groups.
+	remote bool
+}
+
+// appendKey writes the separator and the full key of the pair.
+func (c *logfmtContext) appendKey(key []byte) {
+	c.buf = append(c.buf, ' ')
+	if len(c.key) > 0 {
+		c.buf = append(c.buf, c.key...)
+		c.buf = append(c.buf, '.')
+	}
+	if n := len(c.open); n > 0 && c.open[n-1].keys == len(c.keys) && isLogfmtStageKey(key) {
+		// A context key of the stage must not look the same as its own keys.
+		c.buf = append(c.buf, '_')
+	}
+	c.buf = appendLogfmtKey(c.buf, key)
+	c.buf = append(c.buf, '=')
+}
+
+// appendStageKey writes the separator and the full key of a pair of the error stage itself.
+func (c *logfmtContext) appendStageKey(key string) {
+	c.buf = append(c.buf, ' ')
+	c.buf = append(c.buf, c.key...)
+	c.buf = append(c.buf, '.')
+	c.buf = append(c.buf, key...)
+	c.buf = append(c.buf, '=')
+}
+
+func (c *logfmtContext) text(key []byte, value []byte) {
+	c.appendKey(key)
+	c.buf = appendLogfmtValue(c.buf, value)
+}
+
+// raw writes the value which never needs quoting.
+func (c *logfmtContext) raw(key []byte, value []byte) {
+	c.appendKey(key)
+	c.buf = append(c.buf, value...)
+}
+
+// slice writes the slice rendered into the scratch buffer, keeping the buffer for reuse.
+func (c *logfmtContext) slice(key []byte, value []byte) {
+	c.value = value
+	c.raw(key, value)
+}
+
+func (c *logfmtContext) Bool(key []byte, value bool) {
+	c.appendKey(key)
+	c.buf = strconv.AppendBool(c.buf, value)
+}
+
+func (c *logfmtContext) Time(key []byte, value time.Time) {
+	c.appendKey(key)
+	if c.determined {
+		c.buf = append(c.buf, "<time>"...)
+		return
+	}
+	c.buf = value.AppendFormat(c.buf, time.RFC3339Nano)
+}
+
+func (c *logfmtContext) Duration(key []byte, value time.Duration) {
+	c.appendKey(key)
+	if c.determined {
+		c.buf = append(c.buf, "<duration>"...)
+		return
+	}
+	c.buf = append(c.buf, value.String()...)
+}
+
+func (c *logfmtContext) Int(key []byte, value int) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.int(key, value)
+		return
+	}
+	c.Int64(key, int64(value))
+}
+
+func (c *logfmtContext) Int8(key []byte, value int8)   { c.Int64(key, int64(value)) }
+func (c *logfmtContext) Int16(key []byte, value int16) { c.Int64(key, int64(value)) }
+func (c *logfmtContext) Int32(key []byte, value int32) { c.Int64(key, int64(value)) }
+
+func (c *logfmtContext) Int64(key []byte, value int64) {
+	c.appendKey(key)
+	c.buf = strconv.AppendInt(c.buf, value, 10)
+}
+
+func (c *logfmtContext) Uint(key []byte, value uint)     { c.Uint64(key, uint64(value)) }
+func (c *logfmtContext) Uint8(key []byte, value uint8)   { c.Uint64(key, uint64(value)) }
+func (c *logfmtContext) Uint16(key []byte, value uint16) { c.Uint64(key, uint64(value)) }
+func (c *logfmtContext) Uint32(key []byte, value uint32) { c.Uint64(key, uint64(value)) }
+
+func (c *logfmtContext) Uint64(key []byte, value uint64) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.uint(key, value)
+		return
+	}
+	if len(c.stages) > 0 && string(key) == "@fingerprint" {
+		c.fingerprint = value
+		c.hasFingerprint = true
+		return
+	}
+	c.appendKey(key)
+	c.buf = strconv.AppendUint(c.buf, value, 10)
+}
+
+func (c *logfmtContext) Float32(key []byte, value float32) {
+	c.appendKey(key)
+	c.buf = strconv.AppendFloat(c.buf, float64(value), 'g', -1, 32)
+}
+
+func (c *logfmtContext) Float64(key []byte, value float64) {
+	c.appendKey(key)
+	c.buf = strconv.AppendFloat(c.buf, value, 'g', -1, 64)
+}
+
+func (c *logfmtContext) Str(key []byte, value []byte) {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.str(key, value)
+		return
+	}
+	c.text(key, value)
+}
+
+func (c *logfmtContext) Bytes(key []byte, value []byte) {
+	c.value = base64.RawStdEncoding.AppendEncode(c.value[:0], value)
+	c.raw(key, c.value)
+}
+
+func (c *logfmtContext) RawError(key []byte, value []byte) {
+	c.text(key, value)
+}
+
+func (c *logfmtContext) Secret(key []byte) {
+	c.text(key, []byte(redactedMarker))
+}
+
+func (c *logfmtContext) BoolSlice(key []byte, seq []bool) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, strconv.AppendBool))
+}
+
+func (c *logfmtContext) IntSlice(key []byte, seq []int) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v int) []byte {
+		return strconv.AppendInt(buf, int64(v), 10)
+	}))
+}
+
+func (c *logfmtContext) Int8Slice(key []byte, seq []int8) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *logfmtContext) Int16Slice(key []byte, seq []int16) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *logfmtContext) Int32Slice(key []byte, seq []int32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *logfmtContext) Int64Slice(key []byte, seq []int64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendIntItem))
+}
+
+func (c *logfmtContext) UintSlice(key []byte, seq []uint) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v uint) []byte {
+		return strconv.AppendUint(buf, uint64(v), 10)
+	}))
+}
+
+func (c *logfmtContext) Uint8Slice(key []byte, seq []uint8) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *logfmtContext) Uint16Slice(key []byte, seq []uint16) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *logfmtContext) Uint32Slice(key []byte, seq []uint32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *logfmtContext) Uint64Slice(key []byte, seq []uint64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, appendUintItem))
+}
+
+func (c *logfmtContext) Float32Slice(key []byte, seq []float32) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v float32) []byte {
+		return strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+	}))
+}
+
+func (c *logfmtContext) Float64Slice(key []byte, seq []float64) {
+	c.slice(key, appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v float64) []byte {
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	}))
+}
+
+func (c *logfmtContext) StrSlice(key []byte, seq [][]byte) {
+	c.value = appendLogfmtSlice(c.value[:0], seq, func(buf []byte, v []byte) []byte {
+		return strconv.AppendQuote(buf, unsafe.String(unsafe.SliceData(v), len(v)))
+	})
+	c.text(key, c.value)
+}
+
+func (c *logfmtContext) EnterGroup(key []byte) {
+	if c.stacktrace.depth > 0 || len(c.keys) == 0 && string(key) == "@stack" {
+		c.stacktrace.enter(key)
+		return
+	}
+	c.push(key)
+}
+
+func (c *logfmtContext) LeaveGroup() {
+	if c.stacktrace.depth > 0 {
+		c.stacktrace.leave()
+		return
+	}
+	c.pop()
+}
+
+func (c *logfmtContext) EnterError(key []byte) {
+	c.push(key)
+	c.stages = append(c.stages, 0)
+}
+
+func (c *logfmtContext) EnterErrorStage(state core.ErrorProcessingStage, text []byte) {
+	n := &c.stages[len(c.stages)-1]
+	c.push(strconv.AppendInt(c.value[:0], int64(*n), 10))
+	*n++
+
+	remote := state == core.ErrorProcessingStageRemote
+	c.open = append(c.open, logfmtStage{
+		keys:   len(c.keys),
+		remote: remote,
+	})
+	if remote {
+		// Stages of the remote error are numbered inside the stage.
+		c.stages = append(c.stages, 0)
+	}
+
+	switch state {
+	case core.ErrorProcessingStageNew:
+		c.appendStageKey("stage")
+		c.buf = append(c.buf, "new"...)
+		c.appendStageKey("msg")
+		c.buf = appendLogfmtValue(c.buf, text)
+	case core.ErrorProcessingStageWrap:
+		c.appendStageKey("stage")
+		c.buf = append(c.buf, "wrap"...)
+		c.appendStageKey("msg")
+		c.buf = appendLogfmtValue(c.buf, text)
+	case core.ErrorProcessingStageContext:
+		c.appendStageKey("stage")
+		c.buf = append(c.buf, "ctx"...)
+	case core.ErrorProcessingStageRemote:
+		c.appendStageKey("stage")
+		c.buf = append(c.buf, "remote"...)
+		c.appendStageKey("msg")
+		c.buf = appendLogfmtValue(c.buf, text)
+	}
+}
+
+func (c *logfmtContext) ErrorStageLocation(file []byte, line int) {
+	c.value = c.appendPath(c.value[:0], file)
+	c.value = append(c.value, ':')
+	c.value = strconv.AppendInt(c.value, int64(line), 10)
+	c.appendStageKey("location")
+	c.buf = appendLogfmtValue(c.buf, c.value)
+}
+
+func (c *logfmtContext) LeaveErrorStage() {
+	if c.open[len(c.open)-1].remote {
+		c.stages = c.stages[:len(c.stages)-1]
+	}
+	c.open = c.open[:len(c.open)-1]
+	c.pop()
+}
+
+func (c *logfmtContext) LeaveError(text []byte) {
+	c.text([]byte("text"), text)
+	if c.hasFingerprint {
+		c.raw([]byte("fingerprint"), strconv.AppendUint(c.value[:0], c.fingerprint, 16))
+		c.hasFingerprint = false
+	}
+	c.stages = c.stages[:len(c.stages)-1]
+	c.pop()
+}
+
+func (c *logfmtContext) Finish() {
+	if len(c.stacktrace.text) == 0 {
+		return
+	}
+	if c.determined {
+		c.value = appendDeterministicStacktrace(c.value[:0], c.stacktrace.text)
+		c.text([]byte("stack"), c.value)
+		return
+	}
+	c.text([]byte("stack"), c.stacktrace.text)
+}
+
+// appendPath appends the path of the location, absolute paths are cut to file names in the deterministic mode.
+func (c *logfmtContext) appendPath(buf []byte, file []byte) []byte {
+	if c.determined {
+		return appendDeterministicPath(buf, file)
+	}
+
+	return append(buf, file...)
+}
+
+// appendDeterministicPath appends the path cutting absolute paths to file names.
+func appendDeterministicPath(buf []byte, file []byte) []byte {
+	if filepath.IsAbs(string(file)) || bytes.HasPrefix(file, []byte("/")) {
+		return append(buf, path.Base(filepath.ToSlash(string(file)))...)
+	}
+
+	return append(buf, file...)
+}
+
+// appendDeterministicStacktrace appends the stack trace with paths cut to file names and without offsets of frames.
+func appendDeterministicStacktrace(buf []byte, text []byte) []byte {
+	goroutines := core.ParseStacktrace(text)
+	if len(goroutines) == 0 {
+		return append(buf, text...)
+	}
+
+	appendFrame := func(buf []byte, title string, frame core.StackFrame) []byte {
+		buf = append(buf, title...)
+		buf = append(buf, '\n', '\t')
+		buf = appendDeterministicPath(buf, []byte(frame.File))
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(frame.Line), 10)
+		return append(buf, '\n')
+	}
+	for i, g := range goroutines {
+		if i > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, g.Header...)
+		buf = append(buf, ':', '\n')
+		for _, frame := range g.Frames {
+			buf = appendFrame(buf, frame.Function, frame)
+		}
+		if g.CreatedBy != nil {
+			buf = appendFrame(buf, "created by "+g.CreatedBy.Function, *g.CreatedBy)
+		}
+	}
+
+	return buf
+}
+
+// appendLogfmtKey appends the key replacing characters logfmt keys cannot have with underscores.
+func appendLogfmtKey(buf []byte, key []byte) []byte {
+	if len(key) == 0 {
+		return append(buf, '_')
+	}
+
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7F {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+
+	return buf
+}
+
+// isLogfmtStageKey tells if the key looks the same as keys of error stages: their own keys
+// and numbers of stages of remote errors. Keys starting with the underscore are counted in too,
+// since these are escaped by prepending one.
+func isLogfmtStageKey(key []byte) bool {
+	switch string(key) {
+	case "", "stage", "msg", "location":
+		return true
+	}
+	if key[0] == '_' {
+		return true
+	}
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendLogfmtValue appends the value quoting it when it is empty or has spaces, quotes, equal signs
+// or characters which are not printable.
+func appendLogfmtValue(buf []byte, value []byte) []byte {
+	if len(value) == 0 {
+		return append(buf, `""`...)
+	}
+
+	for _, r := range string(value) {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return strconv.AppendQuote(buf, unsafe.String(unsafe.SliceData(value), len(value)))
+		}
+	}
+
+	return append(buf, value...)
+}
+
+// appendLogfmtSlice appends items of the slice like [1,2,3], without spaces so numbers do not need quoting.
+func appendLogfmtSlice[T any](buf []byte, src []T, appendItem func([]byte, T) []byte) []byte {
+	buf = append(buf, '[')
+	for i, v := range src {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendItem(buf, v)
+	}
+	return append(buf, ']')
+}
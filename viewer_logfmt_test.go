@@ -0,0 +1,123 @@
+package blog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/sirkon/blog/beer"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewLogfmtWriter(&buf).WithTimeUTC())
+	assert.NoError(t, err)
+
+	logger.Info(
+		context.Background(),
+		"request served",
+		Group("request", Str("path", "/users"), Str("query", `name="a b"`), Ints("ids", []int{1, 2})),
+		Duration("took", 1500*time.Millisecond),
+		Strs("tags", []string{"a", "b"}),
+		Str("odd key", "value"),
+		Str("text", "first line\nsecond line"),
+	)
+	logger.Error(context.Background(), "failed", Err(beer.Wrap(beer.New("disk is full").Int("free", 0), "save")))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 2, len(lines), buf.String())
+
+	head, ctx, _ := strings.Cut(lines[0], " msg=")
+	assert.True(t, strings.HasPrefix(head, "time="), head)
+	assert.True(t, strings.HasSuffix(head, "Z level=info"), head)
+	assert.Equal(
+		t,
+		`"request served" request.path=/users request.query="name=\"a b\"" request.ids=[1,2] took=1.5s tags="[\"a\",\"b\"]" odd_key=value text="first line\nsecond line"`,
+		ctx,
+	)
+
+	_, ctx, _ = strings.Cut(lines[1], " level=")
+	assert.Equal(
+		t,
+		`error msg=failed err.0.stage=new err.0.msg="disk is full" err.0.free=0 err.1.stage=wrap err.1.msg=save err.text="save: disk is full"`,
+		ctx,
+	)
+}
+
+func TestAppendLogfmtValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{"a=b", `"a=b"`},
+		{`a\b`, `"a\\b"`},
+		{"тест", "тест"},
+		{"\xff", `"\xff"`},
+		{"tab\there", `"tab\there"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(appendLogfmtValue(nil, []byte(tt.value))), tt.value)
+	}
+}
+
+func TestLogfmtWriterDeterministic(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewLogfmtWriter(&buf).WithDeterministic(), OptionLogLocations())
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "first", Duration("took", time.Second), Time("at", time.Now()))
+	logger.Info(context.Background(), "second")
+
+	assert.Equal(
+		t,
+		"seq=1 level=info location=viewer_logfmt_test.go:74 msg=first took=<duration> at=<time>\n"+
+			"seq=2 level=info location=viewer_logfmt_test.go:75 msg=second\n",
+		buf.String(),
+	)
+}
+
+func TestLogfmtWriterRemoteError(t *testing.T) {
+	data, err := beer.MarshalBinary(beer.Wrap(beer.New("no funds").Int("account-id", 42), "charge"), "billing")
+	assert.NoError(t, err)
+	remote, err := beer.UnmarshalBinary(data)
+	assert.NoError(t, err)
+
+	var buf strings.Builder
+	logger, err := NewLogger(NewLogfmtWriter(&buf).WithDeterministic())
+	assert.NoError(t, err)
+	logger.Error(context.Background(), "failed", Err(beer.Wrap(remote, "pay")))
+
+	assert.Equal(
+		t,
+		`seq=1 level=error msg=failed `+
+			`err.0.stage=remote err.0.msg=billing `+
+			`err.0.0.stage=new err.0.0.msg="no funds" err.0.0.account-id=42 `+
+			`err.0.1.stage=wrap err.0.1.msg=charge `+
+			`err.1.stage=wrap err.1.msg=pay `+
+			`err.text="pay: charge: no funds"`+"\n",
+		buf.String(),
+	)
+}
+
+func TestLogfmtWriterStageKeys(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewLogfmtWriter(&buf).WithDeterministic())
+	assert.NoError(t, err)
+
+	err = beer.New("disk is full").Str("msg", "context").Str("_stage", "escaped").Int("0", 1).Str("location", "here")
+	logger.Error(context.Background(), "failed", Err(err))
+
+	assert.Equal(
+		t,
+		`seq=1 level=error msg=failed `+
+			`err.0.stage=new err.0.msg="disk is full" err.0._msg=context err.0.__stage=escaped err.0._0=1 err.0._location=here `+
+			`err.text="disk is full"`+"\n",
+		buf.String(),
+	)
+}
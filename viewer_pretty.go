@@ -1,15 +1,15 @@
 package blog
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
 	"fmt"
-	"go/token"
 	"io"
 	"os"
 	"strconv"
 	"sync"
+	"time"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/sirkon/blog/internal/core"
@@ -25,33 +25,198 @@ type PrettyWriter struct {
 	branch    []bool
 	colorBack string
 	colorProf *prettyWriterColorProfile
+
+	timeFormat  PrettyTimeFormat
+	timeUTC     bool
+	timeMicro   bool
+	firstTime   time.Time // Time of the first record for PrettyTimeRelative.
+	prevTime    time.Time // Time of the previous record for PrettyTimeDelta.
+	layout      PrettyLayout
+	inlineWidth int
+	width       int // Width of lines of trees values are wrapped to.
+	maxItems    int // Slices longer than this are elided.
+	maxString   int // Strings longer than this are elided.
+	fullStacks  bool
+	determined  bool // Deterministic rendering, see WithDeterministic.
+	seq         int  // Number of the record for PrettyTimeSequence.
+	sources     []string
+	sourceWidth int // Width of the column of sources, the longest alias.
+
+	wrap  []byte   // Scratch buffer of a wrapped value.
+	bools []bool   // Scratch buffer of unpacked bool slices.
+	strs  []string // Scratch buffer of unpacked string slices.
+	path  []byte   // Scratch buffer of a rewritten path.
+
+	locs   prettyLocations
+	glyphs *prettyGlyphs
 }
 
-func NewPrettyWriter(w io.Writer) *PrettyWriter {
-	tree := &packedTree{
-		ctrl: make([]byte, prettyViewNodeSize*128),
-		data: make([]byte, 0, 2048),
+// prettyGlyphs are characters trees and elided values are drawn with.
+type prettyGlyphs struct {
+	branch   string
+	last     string
+	line     string
+	ellipsis string
+	panicked string // Marks the frame which panicked.
+}
+
+var (
+	prettyGlyphsUnicode = &prettyGlyphs{
+		branch:   "├─ ",
+		last:     "└─ ",
+		line:     "│  ",
+		ellipsis: "…",
+		panicked: "▶ ",
 	}
-	ctx := &packedContextDeconstruct{
-		tree: tree,
+	prettyGlyphsASCII = &prettyGlyphs{
+		branch:   "|- ",
+		last:     "`- ",
+		line:     "|  ",
+		ellipsis: "...",
+		panicked: "> ",
 	}
+)
+
+// PrettyTimeFormat is a format of record times of the [PrettyWriter].
+type PrettyTimeFormat int
+
+const (
+	// PrettyTimeDateTime formats times like 2006-01-02 15:04:05.000 in local time.
+	PrettyTimeDateTime PrettyTimeFormat = iota
+	// PrettyTimeRFC3339 formats times like 2006-01-02T15:04:05.000+03:00.
+	PrettyTimeRFC3339
+	// PrettyTimeRelative shows time passed since the first record written, like +00:01:02.003.
+	PrettyTimeRelative
+	// PrettyTimeDelta shows time passed since the previous record written, like +00:00:00.015.
+	PrettyTimeDelta
+	// PrettyTimeSequence shows numbers of records instead of their times, like #000042.
+	PrettyTimeSequence
+)
+
+// PrettyLayout decides how contexts of records are rendered by the [PrettyWriter].
+type PrettyLayout int
+
+const (
+	// PrettyLayoutAuto renders contexts inline unless they have errors or do not fit
+	// into the width set with [PrettyWriter.WithInlineWidth]. They are rendered as trees then.
+	PrettyLayoutAuto PrettyLayout = iota
+	// PrettyLayoutTree always renders contexts as trees.
+	PrettyLayoutTree
+	// PrettyLayoutInline always renders contexts inline, as JSON.
+	PrettyLayoutInline
+)
+
+func NewPrettyWriter(w io.Writer) *PrettyWriter {
 	return &PrettyWriter{
-		w: w,
-		view: &packedDeconstruct{
-			tree: tree,
-			ctx:  ctx,
-		},
+		w:         w,
+		view:      newPackedDeconstruct(),
 		colorProf: &prettyWriterColorProfile{}, // no ANSI colors by default
+		glyphs:    prettyGlyphsUnicode,
 	}
 }
 
+// WithDarkTerminal turns on colors of [ThemeDark] for terminals supporting 256 colors.
 func (g *PrettyWriter) WithDarkTerminal() *PrettyWriter {
-	g.colorProf = newPrettyWriterColorProfileDark()
-	return g
+	return g.WithTheme(ThemeDark(), ColorDepth256)
 }
 
+// WithLightTerminal turns on colors of [ThemeLight] for terminals supporting 256 colors.
 func (g *PrettyWriter) WithLightTerminal() *PrettyWriter {
-	g.colorProf = newPrettyWriterColorProfileLight()
+	return g.WithTheme(ThemeLight(), ColorDepth256)
+}
+
+// WithTheme turns on colors of the theme degraded to the given color depth.
+func (g *PrettyWriter) WithTheme(theme *Theme, depth ColorDepth) *PrettyWriter {
+	g.colorProf = theme.profile(depth)
+	return g
+}
+
+// WithTimeFormat sets a format of record times, [PrettyTimeDateTime] is the default one.
+func (g *PrettyWriter) WithTimeFormat(format PrettyTimeFormat) *PrettyWriter {
+	g.timeFormat = format
+	return g
+}
+
+// WithTimeUTC shows record times in UTC instead of the local time.
+func (g *PrettyWriter) WithTimeUTC() *PrettyWriter {
+	g.timeUTC = true
+	return g
+}
+
+// WithTimeMicroseconds shows record times with microseconds instead of milliseconds.
+func (g *PrettyWriter) WithTimeMicroseconds() *PrettyWriter {
+	g.timeMicro = true
+	return g
+}
+
+// WithLayout sets how contexts of records are rendered, [PrettyLayoutAuto] is the default one.
+func (g *PrettyWriter) WithLayout(layout PrettyLayout) *PrettyWriter {
+	g.layout = layout
+	return g
+}
+
+// WithInlineWidth sets the width of lines [PrettyLayoutAuto] renders contexts inline within.
+// Contexts of longer lines are rendered as trees. Zero means no limit, it is the default.
+func (g *PrettyWriter) WithInlineWidth(width int) *PrettyWriter {
+	g.inlineWidth = width
+	return g
+}
+
+// WithWrapWidth sets the width of lines of trees. Longer values are wrapped under their branches,
+// values of deeply nested nodes may go beyond the width a bit. Zero means no wrapping, it is the default.
+func (g *PrettyWriter) WithWrapWidth(width int) *PrettyWriter {
+	g.width = width
+	return g
+}
+
+// WithMaxSliceItems limits the number of shown slice items. Longer slices are elided,
+// like [1, 2, 3, … 997 more]. Zero means no limit, it is the default.
+func (g *PrettyWriter) WithMaxSliceItems(n int) *PrettyWriter {
+	g.maxItems = max(n, 0)
+	return g
+}
+
+// WithMaxStringLength limits the number of shown characters of strings and base64 encoded bytes.
+// Longer ones are elided, like abc… (997 more). Zero means no limit, it is the default.
+func (g *PrettyWriter) WithMaxStringLength(n int) *PrettyWriter {
+	g.maxString = max(n, 0)
+	return g
+}
+
+// WithFullStacktraces shows all frames of stack traces. Runs of frames of the runtime and of the standard
+// library are folded into a single line by default.
+func (g *PrettyWriter) WithFullStacktraces() *PrettyWriter {
+	g.fullStacks = true
+	return g
+}
+
+// WithDeterministic renders records the same way on every run and on every machine, for golden files
+// of tests: record times are replaced with sequence numbers of [PrettyTimeSequence], values of times
+// and durations with <time> and <duration>, absolute paths are cut to file names unless they are within
+// the root set with [PrettyWriter.WithModuleRoot], offsets of stack frames are omitted, colors and
+// links are off. These take precedence over other settings, no matter in which order they were made.
+func (g *PrettyWriter) WithDeterministic() *PrettyWriter {
+	g.determined = true
+	g.locs.baseNames = true
+	return g
+}
+
+// WithPlain renders records as plain ASCII text, for environments unable to show colors
+// and UTF-8 glyphs: colors and links are turned off, trees are drawn with |- and `-,
+// and elided values are marked with "...".
+func (g *PrettyWriter) WithPlain() *PrettyWriter {
+	g.colorProf = &prettyWriterColorProfile{}
+	g.glyphs = prettyGlyphsASCII
+	g.locs.link = ""
+	return g
+}
+
+// WithSources registers aliases of merged logs, see [MergeReader], to keep the width of their column
+// and their colors from the start. Aliases unknown yet are registered as they come anyway.
+func (g *PrettyWriter) WithSources(aliases ...string) *PrettyWriter {
+	for _, alias := range aliases {
+		g.sourceIndex(alias)
+	}
 	return g
 }
 
@@ -59,9 +224,21 @@ func (g *PrettyWriter) Write(p []byte) (n int, err error) {
 	g.lock.Lock()
 	defer g.lock.Unlock()
 
-	g.view.loc = token.Position{}
-	g.view.tree.Reset()
-	g.view.ctx.Reset()
+	return g.write(-1, p)
+}
+
+// WriteSource writes a record of the merged log with the alias, see [MergeReader]. The alias is shown
+// in the column after the time, colored with [Theme.Sources].
+func (g *PrettyWriter) WriteSource(alias string, p []byte) (n int, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.write(g.sourceIndex(alias), p)
+}
+
+// write renders the record, source is an index of its alias or negative for records without one.
+func (g *PrettyWriter) write(source int, p []byte) (n int, err error) {
+	g.view.reset()
 	g.buf = g.buf[:0]
 	g.stack = g.stack[:0]
 
@@ -72,6 +249,10 @@ func (g *PrettyWriter) Write(p []byte) (n int, err error) {
 	// TODO добавить ANSI-кодов для цвета и прочего
 	g.formatTime()
 	g.buf = append(g.buf, ' ')
+	if source >= 0 {
+		g.formatSource(source)
+		g.buf = append(g.buf, ' ')
+	}
 	g.formatLevel()
 	g.buf = append(g.buf, ' ')
 	if g.view.loc.IsValid() {
@@ -79,7 +260,10 @@ func (g *PrettyWriter) Write(p []byte) (n int, err error) {
 		g.buf = append(g.buf, ' ')
 	}
 
-	if g.view.level != core.LoggingLevelPanic {
+	if g.view.level == core.LoggingLevelPanic {
+		g.view.moveFirst("recovered")
+	}
+	if g.view.level != core.LoggingLevelPanic || !isGzipped(g.view.msg) {
 		g.colorBold()
 		g.buf = append(g.buf, g.view.msg...)
 		g.buf = append(g.buf, ' ', ' ')
@@ -87,35 +271,13 @@ func (g *PrettyWriter) Write(p []byte) (n int, err error) {
 
 		g.setBackCtx()
 		// TODO добавить ANSI для контекста
-		if len(g.view.ctx.errors) == 0 {
-			g.walkJSON()
-		} else {
-			g.buf = append(g.buf, '\n')
-			g.walkTree()
-		}
+		g.formatContext()
 	} else {
+		// Legacy panic records with a gzipped stack trace as a message.
 		g.walkJSON()
-
-		reader, err := gzip.NewReader(bytes.NewReader(g.view.msg))
-		if err != nil {
-			g.colorSTDots()
-			g.buf = append(g.buf, '.', '.', '.', '.')
-			g.colorSTText()
-			g.buf = append(g.buf, err.Error()...)
-			g.buf = append(g.buf, '\n')
-			g.colorReset()
-		} else {
-			scanner := bufio.NewScanner(reader)
-			for scanner.Scan() {
-				g.colorSTDots()
-				g.buf = append(g.buf, '.', '.', '.', '.', ' ')
-				g.colorSTText()
-				g.buf = append(g.buf, scanner.Bytes()...)
-				g.buf = append(g.buf, '\n')
-				g.colorReset()
-			}
-		}
+		g.formatStacktrace(gunzipStacktrace(g.view.msg))
 	}
+	g.formatStacktrace(g.view.ctx.stacktrace.text)
 	g.setBackTxt()
 
 	if _, err := g.w.Write(g.buf); err != nil {
@@ -125,6 +287,80 @@ func (g *PrettyWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Sync syncs the underlying writer if it has Sync() error method.
+func (g *PrettyWriter) Sync() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if s, ok := g.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// sourceIndex returns the index of the alias registering it if needed.
+func (g *PrettyWriter) sourceIndex(alias string) int {
+	for i, s := range g.sources {
+		if s == alias {
+			return i
+		}
+	}
+
+	g.sources = append(g.sources, alias)
+	g.sourceWidth = max(g.sourceWidth, utf8.RuneCountInString(alias))
+	return len(g.sources) - 1
+}
+
+func (g *PrettyWriter) formatSource(index int) {
+	alias := g.sources[index]
+	g.colorSource(index)
+	g.buf = append(g.buf, alias...)
+	g.colorReset()
+	for range g.sourceWidth - utf8.RuneCountInString(alias) {
+		g.buf = append(g.buf, ' ')
+	}
+}
+
+// isGzipped checks for the gzip magic number.
+func isGzipped(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// gunzipStacktrace unpacks a gzipped stack trace, the text of the error is returned if it fails.
+func gunzipStacktrace(gzipped []byte) []byte {
+	reader, err := gzip.NewReader(bytes.NewReader(gzipped))
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	text, err := io.ReadAll(reader)
+	if err != nil {
+		text = append(text, err.Error()...)
+	}
+	return text
+}
+
+func (g *PrettyWriter) formatStacktraceLine(line []byte) {
+	g.colorSTDots()
+	g.buf = append(g.buf, '.', '.', '.', '.', ' ')
+	g.colorSTText()
+	if loc, ok := bytes.CutPrefix(line, []byte{'\t'}); ok {
+		// Locations of frames look like "\t/src/main.go:12 +0x1d".
+		loc, offset, _ := bytes.Cut(loc, []byte(" +0x"))
+		g.buf = append(g.buf, '\t')
+		g.formatLocationText(unsafe.String(unsafe.SliceData(loc), len(loc)))
+		if len(offset) > 0 {
+			g.buf = append(g.buf, " +0x"...)
+			g.buf = append(g.buf, offset...)
+		}
+	} else {
+		g.buf = append(g.buf, line...)
+	}
+	g.buf = append(g.buf, '\n')
+	g.colorReset()
+}
+
 func (g *PrettyWriter) browseCtrl() {
 	clen := g.view.tree.clen
 	var pos int
@@ -135,45 +371,166 @@ func (g *PrettyWriter) browseCtrl() {
 	}
 }
 
+// formatContext renders the context of the record according to the layout.
+func (g *PrettyWriter) formatContext() {
+	switch g.layout {
+	case PrettyLayoutTree:
+		g.buf = append(g.buf, '\n')
+		g.walkTree()
+		return
+	case PrettyLayoutInline:
+		g.walkJSON()
+		return
+	}
+
+	if len(g.view.ctx.errors) > 0 {
+		g.buf = append(g.buf, '\n')
+		g.walkTree()
+		return
+	}
+
+	start := len(g.buf)
+	g.walkJSON()
+	if g.inlineWidth <= 0 || g.view.tree.clen == 0 {
+		return
+	}
+
+	// The line starts at the beginning of the buffer, the stack trace follows it.
+	if visibleWidth(g.buf[:len(g.buf)-1]) > g.inlineWidth {
+		g.buf = append(g.buf[:start], '\n')
+		g.walkTree()
+	}
+}
+
+// visibleWidth computes the width of the text ignoring ANSI color sequences and hyperlinks.
+func visibleWidth(text []byte) int {
+	var res int
+	for len(text) > 0 {
+		if n, _ := escapeLength(text); n > 0 {
+			text = text[n:]
+			continue
+		}
+		_, size := utf8.DecodeRune(text)
+		text = text[size:]
+		res++
+	}
+
+	return res
+}
+
+// escapeLength returns the length of the escape sequence the text starts with, zero if it does not
+// start with one. Color tells if the sequence is a color one, it is an OSC 8 hyperlink otherwise.
+func escapeLength(text []byte) (n int, color bool) {
+	switch {
+	case bytes.HasPrefix(text, []byte("\033[")):
+		if end := bytes.IndexByte(text, 'm'); end >= 0 {
+			return end + 1, true
+		}
+	case bytes.HasPrefix(text, []byte("\033]")):
+		if end := bytes.Index(text, []byte("\033\\")); end >= 0 {
+			return end + 2, false
+		}
+	}
+
+	return 0, false
+}
+
 func (g *PrettyWriter) formatTime() {
 	g.colorTime()
+	defer g.colorReset()
+
 	t := g.view.time
-	g.buf = strconv.AppendInt(g.buf, int64(t.Year()), 10)
-	g.buf = append(g.buf, '-')
-	if t.Month() < 10 {
-		g.buf = append(g.buf, '0')
+	format := g.timeFormat
+	if g.determined {
+		format = PrettyTimeSequence
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(t.Month()), 10)
-	g.buf = append(g.buf, '-')
-	if t.Day() < 10 {
-		g.buf = append(g.buf, '0')
+	switch format {
+	case PrettyTimeRelative:
+		if g.firstTime.IsZero() {
+			g.firstTime = t
+		}
+		g.formatTimeSince(t.Sub(g.firstTime))
+		return
+	case PrettyTimeDelta:
+		if g.prevTime.IsZero() {
+			g.prevTime = t
+		}
+		g.formatTimeSince(t.Sub(g.prevTime))
+		g.prevTime = t
+		return
+	case PrettyTimeSequence:
+		g.seq++
+		g.buf = append(g.buf, '#')
+		g.buf = appendPadded(g.buf, int64(g.seq), 6)
+		return
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(t.Day()), 10)
-	g.buf = append(g.buf, ' ')
-	if t.Hour() < 10 {
-		g.buf = append(g.buf, '0')
+
+	if g.timeUTC {
+		t = t.UTC()
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(t.Hour()), 10)
-	g.buf = append(g.buf, ':')
-	if t.Minute() < 10 {
-		g.buf = append(g.buf, '0')
+	var layout string
+	switch {
+	case format == PrettyTimeRFC3339 && g.timeMicro:
+		layout = "2006-01-02T15:04:05.000000Z07:00"
+	case format == PrettyTimeRFC3339:
+		layout = "2006-01-02T15:04:05.000Z07:00"
+	case g.timeMicro:
+		layout = "2006-01-02 15:04:05.000000"
+	default:
+		layout = "2006-01-02 15:04:05.000"
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(t.Minute()), 10)
-	g.buf = append(g.buf, ':')
-	if t.Second() < 10 {
-		g.buf = append(g.buf, '0')
+	g.buf = t.AppendFormat(g.buf, layout)
+}
+
+// appendTimeValue appends the value of the time attribute, it is a placeholder in the deterministic mode.
+func (g *PrettyWriter) appendTimeValue(buf []byte, nanos int64) []byte {
+	if g.determined {
+		return append(buf, "<time>"...)
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(t.Second()), 10)
+
+	return time.Unix(0, nanos).AppendFormat(buf, time.RFC3339Nano)
+}
+
+// appendDurationValue appends the value of the duration attribute, it is a placeholder in the deterministic mode.
+func (g *PrettyWriter) appendDurationValue(buf []byte, d time.Duration) []byte {
+	if g.determined {
+		return append(buf, "<duration>"...)
+	}
+
+	return append(buf, d.String()...)
+}
+
+// formatTimeSince renders a duration like +01:02:03.004.
+func (g *PrettyWriter) formatTimeSince(d time.Duration) {
+	if d < 0 {
+		g.buf = append(g.buf, '-')
+		d = -d
+	} else {
+		g.buf = append(g.buf, '+')
+	}
+
+	g.buf = appendPadded(g.buf, int64(d/time.Hour), 2)
+	g.buf = append(g.buf, ':')
+	g.buf = appendPadded(g.buf, int64(d/time.Minute%60), 2)
+	g.buf = append(g.buf, ':')
+	g.buf = appendPadded(g.buf, int64(d/time.Second%60), 2)
 	g.buf = append(g.buf, '.')
-	ms := (t.Nanosecond() / 1_000_000) % 1_000
-	if ms < 100 {
-		if ms < 10 {
-			g.buf = append(g.buf, '0')
+	if g.timeMicro {
+		g.buf = appendPadded(g.buf, int64(d/time.Microsecond%1_000_000), 6)
+	} else {
+		g.buf = appendPadded(g.buf, int64(d/time.Millisecond%1_000), 3)
+	}
+}
+
+// appendPadded appends non-negative number padded with zeroes up to the given width.
+func appendPadded(buf []byte, v int64, width int) []byte {
+	for limit := int64(10); width > 1; width-- {
+		if v < limit {
+			buf = append(buf, '0')
 		}
-		g.buf = append(g.buf, ' ')
+		limit *= 10
 	}
-	g.buf = strconv.AppendInt(g.buf, int64(ms), 10)
-	g.colorReset()
+	return strconv.AppendInt(buf, v, 10)
 }
 
 func (g *PrettyWriter) formatLevel() {
@@ -203,18 +560,18 @@ func (g *PrettyWriter) formatLevel() {
 		g.colorLevelPanic()
 		g.buf = append(g.buf, "PANIC"...)
 		g.colorReset()
+	case core.LoggingLevelFatal:
+		g.colorLevelFatal()
+		g.buf = append(g.buf, "FATAL"...)
+		g.colorReset()
 	default:
 		g.buf = append(g.buf, "UNKWN"...)
 	}
 }
 
 func (g *PrettyWriter) formatLocation() {
-	// TODO добавить ANSI
-	loc := g.view.loc
 	g.colorLocation()
-	g.buf = append(g.buf, loc.Filename...)
-	g.buf = append(g.buf, ':')
-	g.buf = strconv.AppendInt(g.buf, int64(loc.Line), 10)
+	g.formatPath(g.view.loc.Filename, g.view.loc.Line)
 	g.colorReset()
 }
 
@@ -1,5 +1,6 @@
 package blog
 
+// prettyWriterColorProfile keeps escape sequences rendered from a [Theme].
 type prettyWriterColorProfile struct {
 	reset  string
 	bold   string
@@ -10,6 +11,7 @@ type prettyWriterColorProfile struct {
 	warn   string
 	error  string
 	panic  string
+	fatal  string
 	loc    string // Color of locations for log itself and @location of error
 	link   string // Hierarchy links of tree.
 	stdots string
@@ -17,52 +19,24 @@ type prettyWriterColorProfile struct {
 	key    string
 	errkey string
 	ctx    string
+	source []string // Aliases of merged logs, used in turn.
 }
 
-func newPrettyWriterColorProfileDark() *prettyWriterColorProfile {
-	return &prettyWriterColorProfile{
-		reset:  "\033[0m",
-		bold:   "\033[1m",
-		time:   "\033[35m",
-		trace:  "\033[90m",
-		debug:  "\033[36m",
-		info:   "\033[32m",
-		warn:   "\033[33m",
-		error:  "\033[31m",
-		panic:  "\033[1;41;97m",
-		loc:    "\033[38;5;244m",
-		link:   "\033[38;5;240m",
-		stdots: "\033[38;5;236m",
-		sttext: "\033[38;5;245m",
-		key:    "\033[38;5;109m",
-		errkey: "\033[38;5;203m",
-		ctx:    "\033[38;5;252m",
-	}
-}
+// prettyNoColors is a profile of writers without colors.
+var prettyNoColors prettyWriterColorProfile
 
-func newPrettyWriterColorProfileLight() *prettyWriterColorProfile {
-	return &prettyWriterColorProfile{
-		reset:  "\033[0m",
-		bold:   "\033[1m",
-		time:   "\033[95m",
-		trace:  "\033[90m",
-		debug:  "\033[36m",
-		info:   "\033[32m",
-		warn:   "\033[33m",
-		error:  "\033[31m",
-		panic:  "\033[1;41;97m",
-		loc:    "\033[38;5;240m",
-		link:   "\033[38;5;248m",
-		stdots: "\033[38;5;252m",
-		sttext: "\033[38;5;240m",
-		key:    "\033[38;5;31m",
-		errkey: "\033[38;5;203m",
-		ctx:    "\033[38;5;238m",
+// profile returns the color profile records are rendered with, colors are off in the deterministic mode.
+func (g *PrettyWriter) profile() *prettyWriterColorProfile {
+	if g.determined {
+		return &prettyNoColors
 	}
+
+	return g.colorProf
 }
+
 func (g *PrettyWriter) colorReset() {
 	if g.colorBack == "" {
-		g.buf = append(g.buf, g.colorProf.reset...)
+		g.buf = append(g.buf, g.profile().reset...)
 		return
 	}
 
@@ -70,13 +44,13 @@ func (g *PrettyWriter) colorReset() {
 }
 
 func (g *PrettyWriter) setBackCtx() {
-	g.colorBack = g.colorProf.ctx
-	g.buf = append(g.buf, g.colorProf.ctx...)
+	g.colorBack = g.profile().ctx
+	g.buf = append(g.buf, g.profile().ctx...)
 }
 
 func (g *PrettyWriter) setBackTxt() {
 	g.colorBack = ""
-	g.buf = append(g.buf, g.colorProf.reset...)
+	g.buf = append(g.buf, g.profile().reset...)
 }
 
 func (g *PrettyWriter) colorSetBack(back string) {
@@ -85,57 +59,69 @@ func (g *PrettyWriter) colorSetBack(back string) {
 }
 
 func (g *PrettyWriter) colorBold() {
-	g.buf = append(g.buf, g.colorProf.bold...)
+	g.buf = append(g.buf, g.profile().bold...)
 }
 
 func (g *PrettyWriter) colorLevelTrace() {
-	g.buf = append(g.buf, g.colorProf.trace...)
+	g.buf = append(g.buf, g.profile().trace...)
 }
 
 func (g *PrettyWriter) colorLevelDebug() {
-	g.buf = append(g.buf, g.colorProf.debug...)
+	g.buf = append(g.buf, g.profile().debug...)
 }
 
 func (g *PrettyWriter) colorLevelInfo() {
-	g.buf = append(g.buf, g.colorProf.info...)
+	g.buf = append(g.buf, g.profile().info...)
 }
 
 func (g *PrettyWriter) colorLevelWarn() {
-	g.buf = append(g.buf, g.colorProf.warn...)
+	g.buf = append(g.buf, g.profile().warn...)
 }
 
 func (g *PrettyWriter) colorLevelError() {
-	g.buf = append(g.buf, g.colorProf.error...)
+	g.buf = append(g.buf, g.profile().error...)
 }
 
 func (g *PrettyWriter) colorLevelPanic() {
-	g.buf = append(g.buf, g.colorProf.panic...)
+	g.buf = append(g.buf, g.profile().panic...)
+}
+
+func (g *PrettyWriter) colorLevelFatal() {
+	g.buf = append(g.buf, g.profile().fatal...)
 }
 
 func (g *PrettyWriter) colorLocation() {
-	g.buf = append(g.buf, g.colorProf.loc...)
+	g.buf = append(g.buf, g.profile().loc...)
 }
 
 func (g *PrettyWriter) colorTime() {
-	g.buf = append(g.buf, g.colorProf.time...)
+	g.buf = append(g.buf, g.profile().time...)
 }
 
 func (g *PrettyWriter) colorLink() {
-	g.buf = append(g.buf, g.colorProf.link...)
+	g.buf = append(g.buf, g.profile().link...)
 }
 
 func (g *PrettyWriter) colorSTDots() {
-	g.buf = append(g.buf, g.colorProf.stdots...)
+	g.buf = append(g.buf, g.profile().stdots...)
 }
 
 func (g *PrettyWriter) colorSTText() {
-	g.buf = append(g.buf, g.colorProf.sttext...)
+	g.buf = append(g.buf, g.profile().sttext...)
 }
 
 func (g *PrettyWriter) colorKey() {
-	g.buf = append(g.buf, g.colorProf.key...)
+	g.buf = append(g.buf, g.profile().key...)
 }
 
 func (g *PrettyWriter) colorErrKey() {
-	g.buf = append(g.buf, g.colorProf.errkey...)
+	g.buf = append(g.buf, g.profile().errkey...)
+}
+
+func (g *PrettyWriter) colorSource(index int) {
+	source := g.profile().source
+	if len(source) == 0 {
+		return
+	}
+	g.buf = append(g.buf, source[index%len(source)]...)
 }
@@ -19,6 +19,67 @@ type packedDeconstruct struct {
 	ctx  *packedContextDeconstruct
 }
 
+func newPackedDeconstruct() *packedDeconstruct {
+	tree := &packedTree{
+		ctrl: make([]byte, prettyViewNodeSize*128),
+		data: make([]byte, 0, 2048),
+	}
+	return &packedDeconstruct{
+		tree: tree,
+		ctx: &packedContextDeconstruct{
+			tree: tree,
+		},
+	}
+}
+
+// reset prepares the deconstruct for the next record.
+func (p *packedDeconstruct) reset() {
+	p.loc = token.Position{}
+	p.tree.Reset()
+	p.ctx.Reset()
+}
+
+// moveFirst moves the top level node with the key to the head of the context. Nodes are walked
+// from the zero offset, so the node swaps its place in the control buffer with the head one.
+func (p *packedDeconstruct) moveFirst(key string) {
+	t := p.tree
+	if t.clen == 0 {
+		return
+	}
+
+	base := unsafe.Pointer(unsafe.SliceData(t.ctrl))
+	head := (*prettyViewNode)(base)
+	prev := head
+	for prev.next != 0 {
+		pos := prev.next
+		node := (*prettyViewNode)(unsafe.Add(base, pos))
+		if t.unpackKey(node) != key {
+			prev = node
+			continue
+		}
+
+		next := node.next
+		*head, *node = *node, *head
+		head.next = pos
+		if prev == head {
+			// The node followed the head, the former head is right after it now.
+			node.next = next
+		} else {
+			prev.next = next
+		}
+
+		for i, errpos := range p.ctx.errors {
+			switch errpos {
+			case 0:
+				p.ctx.errors[i] = int(pos)
+			case int(pos):
+				p.ctx.errors[i] = 0
+			}
+		}
+		return
+	}
+}
+
 func (p *packedDeconstruct) Time(t time.Time) {
 	p.time = t
 }
@@ -48,12 +109,24 @@ type packedContextDeconstruct struct {
 	stack    []int
 	tree     *packedTree
 	stageBuf []byte
+
+	// errDepth counts errors being processed, fingerprint is the pending
+	// fingerprint of the current one, shown after its @text.
+	errDepth       int
+	fingerprint    uint64
+	hasFingerprint bool
+
+	// stacktrace collects a stack logged with the @stack key, it is rendered after the context.
+	stacktrace prettyStacktrace
 }
 
 func (p *packedContextDeconstruct) Reset() {
 	p.errors = p.errors[:0]
 	p.stack = p.stack[:0]
 	p.prev = -1
+	p.errDepth = 0
+	p.hasFingerprint = false
+	p.stacktrace.reset()
 }
 
 func (p *packedContextDeconstruct) Bool(key []byte, value bool) {
@@ -69,6 +142,10 @@ func (p *packedContextDeconstruct) Duration(key []byte, value time.Duration) {
 }
 
 func (p *packedContextDeconstruct) Int(key []byte, value int) {
+	if p.stacktrace.depth > 0 {
+		p.stacktrace.int(key, value)
+		return
+	}
 	p.prev = p.tree.AddInt(p.prev, key, int64(value))
 }
 
@@ -105,6 +182,15 @@ func (p *packedContextDeconstruct) Uint32(key []byte, value uint32) {
 }
 
 func (p *packedContextDeconstruct) Uint64(key []byte, value uint64) {
+	if p.stacktrace.depth > 0 {
+		p.stacktrace.uint(key, value)
+		return
+	}
+	if p.errDepth > 0 && string(key) == "@fingerprint" {
+		p.fingerprint = value
+		p.hasFingerprint = true
+		return
+	}
 	p.prev = p.tree.AddUint(p.prev, key, value)
 }
 
@@ -117,6 +203,10 @@ func (p *packedContextDeconstruct) Float64(key []byte, value float64) {
 }
 
 func (p *packedContextDeconstruct) Str(key []byte, value []byte) {
+	if p.stacktrace.depth > 0 {
+		p.stacktrace.str(key, value)
+		return
+	}
 	p.prev = p.tree.AddString(p.prev, key, value)
 }
 
@@ -129,6 +219,10 @@ func (p *packedContextDeconstruct) RawError(key []byte, value []byte) {
 	p.errors = append(p.errors, p.prev)
 }
 
+func (p *packedContextDeconstruct) Secret(key []byte) {
+	p.prev = p.tree.AddSecret(p.prev, key)
+}
+
 func (p *packedContextDeconstruct) BoolSlice(key []byte, seq []bool) {
 	p.prev = p.tree.AddBoolArray(p.prev, key, seq)
 }
@@ -186,11 +280,19 @@ func (p *packedContextDeconstruct) StrSlice(key []byte, seq [][]byte) {
 }
 
 func (p *packedContextDeconstruct) EnterGroup(key []byte) {
+	if p.stacktrace.depth > 0 || len(p.stack) == 0 && p.errDepth == 0 && string(key) == "@stack" {
+		p.stacktrace.enter(key)
+		return
+	}
 	p.prev = p.tree.AddObjectRoot(p.prev, key)
 	p.stack = append(p.stack, p.prev)
 }
 
 func (p *packedContextDeconstruct) LeaveGroup() {
+	if p.stacktrace.depth > 0 {
+		p.stacktrace.leave()
+		return
+	}
 	p.tree.CloseObjectRoot(p.prev)
 	p.prev = p.stack[len(p.stack)-1]
 	p.stack = p.stack[:len(p.stack)-1]
@@ -200,6 +302,7 @@ func (p *packedContextDeconstruct) EnterError(key []byte) {
 	p.prev = p.tree.AddObjectRoot(p.prev, key)
 	p.stack = append(p.stack, p.prev)
 	p.errors = append(p.errors, p.prev)
+	p.errDepth++
 	ctxKey := "@context"
 	p.prev = p.tree.AddObjectRoot(p.prev, unsafe.Slice(unsafe.StringData(ctxKey), len(ctxKey)))
 	p.stack = append(p.stack, p.prev)
@@ -216,6 +319,9 @@ func (p *packedContextDeconstruct) EnterErrorStage(state core.ErrorProcessingSta
 		p.stageBuf = append(p.stageBuf, text...)
 	case core.ErrorProcessingStageContext:
 		p.stageBuf = append(p.stageBuf, "CTX"...)
+	case core.ErrorProcessingStageRemote:
+		p.stageBuf = append(p.stageBuf, "REMOTE: "...)
+		p.stageBuf = append(p.stageBuf, text...)
 	}
 	p.prev = p.tree.AddObjectRoot(p.prev, p.stageBuf)
 	p.stack = append(p.stack, p.prev)
@@ -254,6 +360,20 @@ func (p *packedContextDeconstruct) LeaveError(text []byte) {
 	)
 	p.errors = append(p.errors, p.prev)
 
+	// Add @fingerprint
+	if p.hasFingerprint {
+		p.stageBuf = p.stageBuf[:0]
+		p.stageBuf = strconv.AppendUint(p.stageBuf, p.fingerprint, 16)
+		fpText := "@fingerprint"
+		p.prev = p.tree.AddString(
+			p.prev,
+			unsafe.Slice(unsafe.StringData(fpText), len(fpText)),
+			p.stageBuf,
+		)
+		p.hasFingerprint = false
+	}
+	p.errDepth--
+
 	// Close error
 	p.prev = p.stack[len(p.stack)-1]
 	p.stack = p.stack[:len(p.stack)-1]
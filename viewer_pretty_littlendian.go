@@ -4,7 +4,6 @@ package blog
 
 import (
 	"math/bits"
-	"strconv"
 	"unsafe"
 )
 
@@ -47,48 +46,19 @@ func unpackShortStringValue(n *prettyViewNode, shortPlaceholder *uint64, longPla
 	return longPlaceholder[:length]
 }
 
-func (g *PrettyWriter) unpackBoolsJSON(node *prettyViewNode) {
-	g.buf = append(g.buf, '[')
+// unpackBools appends elements of the bool slice packed as a bit set.
+func unpackBools(dst []bool, node *prettyViewNode, t *packedTree) []bool {
 	bytesNo := (node.misc + 7) / 8
 	rest := node.misc
-	src := unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(g.view.tree.data)), node.kind>>32)), bytesNo)
+	src := unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), node.kind>>32)), bytesNo)
 	for _, b := range src {
 		l := min(8, rest)
 		for range l {
-			if b&0x01 > 0 {
-				g.buf = append(g.buf, "true, "...)
-			} else {
-				g.buf = append(g.buf, "false, "...)
-			}
+			dst = append(dst, b&0x01 > 0)
 			b >>= 1
 		}
+		rest -= l
 	}
-	g.buf = g.buf[:len(g.buf)-2]
-	g.buf = append(g.buf, ']')
-}
-
-func (g *PrettyWriter) unpackBoolsTree(node *prettyViewNode) {
-	bytesNo := (node.misc + 7) / 8
-	rest := node.misc
-	src := unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(g.view.tree.data)), node.kind>>32)), bytesNo)
 
-	g.buf = append(g.buf, '\n')
-	g.branch = append(g.branch, true)
-	for i, b := range src {
-		l := min(8, rest)
-		for j := range l {
-			g.drawPrefix(g.branch, i*8+int(j) == int(node.misc)-1, node.next == 0)
-			g.buf = strconv.AppendInt(g.buf, int64(i*8+int(j)), 10)
-			g.buf = append(g.buf, ':', ' ')
-			if b&0x01 > 0 {
-				g.buf = append(g.buf, "true"...)
-			} else {
-				g.buf = append(g.buf, "false"...)
-			}
-			g.buf = append(g.buf, '\n')
-			b >>= 1
-		}
-	}
-	g.buf = g.buf[:len(g.buf)-1]
-	g.branch = g.branch[:len(g.branch)-1]
+	return dst
 }
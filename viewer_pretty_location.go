@@ -0,0 +1,192 @@
+package blog
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Templates of links for [PrettyWriter.WithLinks]. {path} is replaced with the absolute path
+// of the file and {line} with the line number.
+const (
+	LinkFile   = "file://{path}"
+	LinkVSCode = "vscode://file{path}:{line}"
+	LinkIDEA   = "idea://open?file={path}&line={line}"
+)
+
+// prettyLocations describes how the [PrettyWriter] shows locations.
+type prettyLocations struct {
+	mappings []prettyPathMapping
+	root     string
+	modCache bool
+	link     string
+	// baseNames cuts absolute paths which were not shortened otherwise to their file names.
+	baseNames bool
+}
+
+type prettyPathMapping struct {
+	from string
+	to   string
+}
+
+// WithPathMapping replaces the from prefix of paths of locations with the to one. It is meant
+// to map paths of the build machine to a local checkout, so locations and their links point
+// to local files. Mappings are tried in the order they were added, the first matching one is used.
+func (g *PrettyWriter) WithPathMapping(from, to string) *PrettyWriter {
+	g.locs.mappings = append(g.locs.mappings, prettyPathMapping{
+		from: from,
+		to:   to,
+	})
+	return g
+}
+
+// WithModuleRoot shows paths of locations within the root relative to it, like ./internal/core/logger.go.
+// Mappings of [PrettyWriter.WithPathMapping] are applied before.
+func (g *PrettyWriter) WithModuleRoot(root string) *PrettyWriter {
+	g.locs.root = strings.TrimSuffix(filepath.ToSlash(root), "/")
+	return g
+}
+
+// WithModuleCachePaths shows paths of locations within the module cache as module@version/path,
+// like github.com/sirkon/blog@v1.2.3/logger.go. The cache is recognized by /pkg/mod/ part of
+// paths, so paths of other machines are shortened too.
+func (g *PrettyWriter) WithModuleCachePaths() *PrettyWriter {
+	g.locs.modCache = true
+	return g
+}
+
+// WithLinks makes locations OSC 8 hyperlinks, so terminals supporting them open files on clicks.
+// The template is either one of [LinkFile], [LinkVSCode], [LinkIDEA] or a custom one with
+// {path} and {line} placeholders. Only locations with absolute paths are linked.
+func (g *PrettyWriter) WithLinks(template string) *PrettyWriter {
+	g.locs.link = template
+	return g
+}
+
+// formatPath renders a location made of the path and the line number, line is not rendered if it is zero.
+// The path is rewritten and linked according to the settings of the writer.
+func (g *PrettyWriter) formatPath(file string, line int) {
+	for _, m := range g.locs.mappings {
+		if rest, ok := strings.CutPrefix(file, m.from); ok {
+			g.path = append(append(g.path[:0], m.to...), rest...)
+			file = unsafe.String(unsafe.SliceData(g.path), len(g.path))
+			break
+		}
+	}
+
+	link := g.locs.link != "" && !g.determined && filepath.IsAbs(file)
+	if link {
+		g.buf = append(g.buf, "\033]8;;"...)
+		g.buf = appendLink(g.buf, g.locs.link, file, line)
+		g.buf = append(g.buf, "\033\\"...)
+	}
+
+	g.buf = g.locs.appendShortPath(g.buf, file)
+	if line > 0 {
+		g.buf = append(g.buf, ':')
+		g.buf = strconv.AppendInt(g.buf, int64(line), 10)
+	}
+
+	if link {
+		g.buf = append(g.buf, "\033]8;;\033\\"...)
+	}
+}
+
+// formatLocationText renders a location looking like path:line.
+func (g *PrettyWriter) formatLocationText(text string) {
+	pos := strings.LastIndexByte(text, ':')
+	if pos < 0 {
+		g.formatPath(text, 0)
+		return
+	}
+
+	line, err := strconv.Atoi(text[pos+1:])
+	if err != nil || line <= 0 {
+		g.formatPath(text, 0)
+		return
+	}
+
+	g.formatPath(text[:pos], line)
+}
+
+// appendShortPath appends the path relative to the module root or to the module cache.
+// Other absolute paths are cut to their file names if baseNames is set.
+func (l *prettyLocations) appendShortPath(buf []byte, file string) []byte {
+	if l.root != "" {
+		slashed := filepath.ToSlash(file)
+		if rest, ok := strings.CutPrefix(slashed, l.root); ok && strings.HasPrefix(rest, "/") {
+			buf = append(buf, '.')
+			return append(buf, rest...)
+		}
+	}
+
+	if l.modCache {
+		slashed := filepath.ToSlash(file)
+		if _, rest, ok := strings.Cut(slashed, "/pkg/mod/"); ok {
+			// Upper case letters are escaped in the cache, like !sirkon for Sirkon.
+			for i := 0; i < len(rest); i++ {
+				c := rest[i]
+				if c == '!' && i+1 < len(rest) {
+					i++
+					c = rest[i] - 'a' + 'A'
+				}
+				buf = append(buf, c)
+			}
+			return buf
+		}
+	}
+
+	if l.baseNames && (filepath.IsAbs(file) || strings.HasPrefix(file, "/")) {
+		return append(buf, path.Base(filepath.ToSlash(file))...)
+	}
+
+	return append(buf, file...)
+}
+
+// appendLink appends an URL made of the template.
+func appendLink(buf []byte, template string, file string, line int) []byte {
+	for {
+		before, after, ok := strings.Cut(template, "{")
+		buf = append(buf, before...)
+		if !ok {
+			return buf
+		}
+
+		switch {
+		case strings.HasPrefix(after, "path}"):
+			slashed := filepath.ToSlash(file)
+			if !strings.HasPrefix(slashed, "/") {
+				// Windows paths like C:/src/main.go.
+				buf = append(buf, '/')
+			}
+			buf = appendURLPath(buf, slashed)
+			template = after[len("path}"):]
+		case strings.HasPrefix(after, "line}"):
+			buf = strconv.AppendInt(buf, int64(line), 10)
+			template = after[len("line}"):]
+		default:
+			buf = append(buf, '{')
+			template = after
+		}
+	}
+}
+
+// appendURLPath appends the path escaping characters not allowed in URL paths.
+func appendURLPath(buf []byte, path string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			buf = append(buf, c)
+		case strings.IndexByte("/-._~:@!$'()*,", c) >= 0:
+			buf = append(buf, c)
+		default:
+			buf = append(buf, '%', hex[c>>4], hex[c&0x0F])
+		}
+	}
+
+	return buf
+}
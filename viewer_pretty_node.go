@@ -10,6 +10,9 @@ import (
 
 const maxStringLen = 1 << 20
 
+// redactedMarker is shown instead of values of secrets.
+const redactedMarker = "[REDACTED]"
+
 type packedTree struct {
 	clen int
 	ctrl []byte
@@ -50,7 +53,7 @@ func (t *packedTree) CloseObjectRoot(prev int) int {
 
 	base := unsafe.Pointer(unsafe.SliceData(t.ctrl))
 	respt := (*prettyViewNode)(unsafe.Add(base, prev))
-	if respt.kind&0x1F != prettyViewKindRoot {
+	if respt.kind&0x1F != prettyViewKindRoot || respt.misc != 0 {
 		// Meaning the root node was added some child nodes, no need to finish it explicitly.
 		return t.clen
 	}
@@ -150,6 +153,19 @@ func (t *packedTree) AddBool(prev int, key []byte, data bool) int {
 	return off
 }
 
+func (t *packedTree) AddSecret(prev int, key []byte) int {
+	off := t.clen
+	t.ensureSpace()
+	base := unsafe.Pointer(unsafe.SliceData(t.ctrl))
+	t.linkToPrev(prev, off)
+	respt := (*prettyViewNode)(unsafe.Add(base, off))
+	*respt = prettyViewNode{
+		key:  t.packKey(key),
+		kind: prettyViewKindValueSecret,
+	}
+	return off
+}
+
 func (t *packedTree) AddIntSlice(
 	prev int,
 	key []byte,
@@ -427,6 +443,7 @@ const (
 	prettyViewKindValueFloat32Slice
 	prettyViewKindValueFloat64Slice
 	prettyViewKindValueStringSlice
+	prettyViewKindValueSecret // Has no value, it was redacted.
 
 	// *Int note:
 	//  - If 7 or fewer bytes are enough for keeping a value it is encoded right in the kind where
@@ -519,6 +536,8 @@ func (k prettyViewKind) String() string {
 		return "[]float64"
 	case prettyViewKindValueStringSlice:
 		return "[]string"
+	case prettyViewKindValueSecret:
+		return "secret"
 	default:
 		// Используем strconv для формирования fallback-строки без fmt
 		return "pretty-view-kind-value-unknown(" + strconv.FormatUint(uint64(k), 10) + ")"
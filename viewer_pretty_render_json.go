@@ -58,14 +58,11 @@ mainLoop:
 			}
 		case prettyViewKindValueTime:
 			g.buf = append(g.buf, '"')
-			g.buf = append(
-				g.buf,
-				time.Unix(0, int64(unpackFullNum(node.kind, node.misc))).Format(time.RFC3339Nano)...,
-			)
+			g.buf = g.appendTimeValue(g.buf, int64(unpackFullNum(node.kind, node.misc)))
 			g.buf = append(g.buf, '"')
 		case prettyViewKindValueDuration:
 			g.buf = append(g.buf, '"')
-			g.buf = append(g.buf, time.Duration(unpackFullNum(node.kind, node.misc)).String()...)
+			g.buf = g.appendDurationValue(g.buf, time.Duration(unpackFullNum(node.kind, node.misc)))
 			g.buf = append(g.buf, '"')
 		case prettyViewKindValueInt:
 			g.buf = strconv.AppendInt(g.buf, int64(unpackFullNum(node.kind, node.misc)), 10)
@@ -80,12 +77,18 @@ mainLoop:
 				(*byte)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)),
 				node.misc,
 			)
-			g.buf = strconv.AppendQuote(g.buf, value)
+			if key == "@location" {
+				g.buf = append(g.buf, '"')
+				g.formatLocationText(value)
+				g.buf = append(g.buf, '"')
+				break
+			}
+			g.buf = g.appendQuotedText(g.buf, value)
 		case prettyViewKindValueStringShort:
 			var shortPlace uint64
 			var longPlace [16]byte
 			value := unpackShortStringValue(node, &shortPlace, longPlace)
-			g.buf = strconv.AppendQuote(g.buf, unsafe.String(unsafe.SliceData(value), len(value)))
+			g.buf = g.appendQuotedText(g.buf, unsafe.String(unsafe.SliceData(value), len(value)))
 		case prettyViewKindValueByteSlice:
 			off := node.kind >> 32
 			value := unsafe.Slice(
@@ -93,7 +96,7 @@ mainLoop:
 				node.misc,
 			)
 			g.buf = append(g.buf, '"')
-			g.buf = base64.URLEncoding.AppendEncode(g.buf, value)
+			g.buf = g.appendBase64(g.buf, base64.URLEncoding, value)
 			g.buf = append(g.buf, '"')
 		case prettyViewKindValueByteSliceShort:
 			var shortPlace uint64
@@ -103,49 +106,14 @@ mainLoop:
 			g.buf = base64.URLEncoding.AppendEncode(g.buf, value)
 			g.buf = append(g.buf, '"')
 		case prettyViewKindValueBoolSlice:
-			g.unpackBoolsJSON(node)
+			g.bools = unpackBools(g.bools[:0], node, g.view.tree)
+			g.formatBoolsJSON()
 		case prettyViewKindValueBoolSliceShort:
-			g.buf = append(g.buf, '[')
-			length := node.kind << 52 >> 57
-			if length == 0 {
-				g.buf = append(g.buf, ']')
-				break
-			}
-			part1 := node.kind >> 12
-			part2 := node.misc
-			if length <= 52 {
-				for range length {
-					if part1&0x01 != 0 {
-						g.buf = append(g.buf, "true, "...)
-					} else {
-						g.buf = append(g.buf, "false, "...)
-					}
-					part1 >>= 1
-				}
-			} else {
-				for range 52 {
-					if part1&0x01 != 0 {
-						g.buf = append(g.buf, "true, "...)
-					} else {
-						g.buf = append(g.buf, "false, "...)
-					}
-					part1 >>= 1
-				}
-				length -= 52
-				for range length {
-					if part2&0x01 != 0 {
-						g.buf = append(g.buf, "true, "...)
-					} else {
-						g.buf = append(g.buf, "false, "...)
-					}
-					part2 >>= 1
-				}
-			}
-			g.buf = g.buf[:len(g.buf)-2]
-			g.buf = append(g.buf, ']')
+			g.bools = unpackShortBools(g.bools[:0], node)
+			g.formatBoolsJSON()
 		case prettyViewKindValueIntSlice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*int)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*int)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -153,10 +121,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendInt(g.buf, int64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueInt8Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*int8)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*int8)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -164,10 +133,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendInt(g.buf, int64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueInt16Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*int16)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*int16)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -175,10 +145,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendInt(g.buf, int64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueInt32Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*int32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*int32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -186,10 +157,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendInt(g.buf, int64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueInt64Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*int64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*int64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -197,10 +169,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendInt(g.buf, int64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueUintSlice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*uint)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*uint)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -208,10 +181,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueUint8Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*uint8)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*uint8)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -219,10 +193,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueUint16Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*uint16)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*uint16)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -230,10 +205,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueUint32Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*uint32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*uint32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -241,10 +217,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueUint64Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*uint64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*uint64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -252,10 +229,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueFloat32Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*float32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*float32)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -263,10 +241,11 @@ mainLoop:
 				}
 				g.buf = strconv.AppendFloat(g.buf, float64(v), 'g', -1, 32)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		case prettyViewKindValueFloat64Slice:
 			off := node.kind >> 32
-			src := unsafe.Slice((*float64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
+			src, more := elideSlice(unsafe.Slice((*float64)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc), g.maxItems)
 			g.buf = append(g.buf, '[')
 			for i, v := range src {
 				if i > 0 {
@@ -274,20 +253,21 @@ mainLoop:
 				}
 				g.buf = strconv.AppendFloat(g.buf, float64(v), 'g', -1, 64)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
+		case prettyViewKindValueSecret:
+			g.buf = strconv.AppendQuote(g.buf, redactedMarker)
 		case prettyViewKindValueStringSlice:
+			g.strs = unpackStrings(g.strs[:0], node, t)
+			src, more := elideSlice(g.strs, g.maxItems)
 			g.buf = append(g.buf, '[')
-			src := unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), node.kind>>32)
-			length := int(node.misc)
-			for i := range length {
-				lens := *(*uint32)(src)
+			for i, str := range src {
 				if i > 0 {
 					g.buf = append(g.buf, ',', ' ')
 				}
-				str := unsafe.String((*byte)(unsafe.Add(src, 4)), lens)
-				g.buf = strconv.AppendQuote(g.buf, str)
-				src = unsafe.Add(src, 4+lens)
+				g.buf = g.appendQuotedText(g.buf, str)
 			}
+			g.buf = g.appendMoreItems(g.buf, more)
 			g.buf = append(g.buf, ']')
 		default:
 			g.buf = strconv.AppendQuote(g.buf, (node.kind & 0x1F).String())
@@ -311,3 +291,17 @@ mainLoop:
 	g.stack = stack
 	g.buf = append(g.buf, '}', '\n')
 }
+
+// formatBoolsJSON renders unpacked bools.
+func (g *PrettyWriter) formatBoolsJSON() {
+	src, more := elideSlice(g.bools, g.maxItems)
+	g.buf = append(g.buf, '[')
+	for i, v := range src {
+		if i > 0 {
+			g.buf = append(g.buf, ',', ' ')
+		}
+		g.buf = strconv.AppendBool(g.buf, v)
+	}
+	g.buf = g.appendMoreItems(g.buf, more)
+	g.buf = append(g.buf, ']')
+}
@@ -3,6 +3,7 @@ package blog
 import (
 	"encoding/base64"
 	"math"
+	"slices"
 	"strconv"
 	"time"
 	"unsafe"
@@ -30,15 +31,10 @@ mainLoop:
 		last := node.next == 0
 
 		// draw tree prefix
-		g.drawPrefix(branch, last, false)
+		start := len(g.buf)
+		g.drawPrefix(branch, last)
 
-		var errFound bool
-		for _, pp := range errps {
-			if pp == pos {
-				errFound = true
-			}
-		}
-		errFound = errFound
+		errFound := slices.Contains(errps, pos)
 
 		if !errFound {
 			g.colorKey()
@@ -47,6 +43,10 @@ mainLoop:
 		}
 		g.buf = append(g.buf, key...)
 		g.colorReset()
+		value := len(g.buf)
+
+		// Nested lines of values are drawn under the branch of the node.
+		g.branch = append(branch, !last)
 
 		switch node.kind & 0x1F {
 
@@ -75,23 +75,12 @@ mainLoop:
 		case prettyViewKindValueTime:
 
 			g.buf = append(g.buf, ':', ' ')
-			g.buf = append(
-				g.buf,
-				time.Unix(
-					0,
-					int64(unpackFullNum(node.kind, node.misc)),
-				).Format(time.RFC3339Nano)...,
-			)
+			g.buf = g.appendTimeValue(g.buf, int64(unpackFullNum(node.kind, node.misc)))
 
 		case prettyViewKindValueDuration:
 
 			g.buf = append(g.buf, ':', ' ')
-			g.buf = append(
-				g.buf,
-				time.Duration(
-					unpackFullNum(node.kind, node.misc),
-				).String()...,
-			)
+			g.buf = g.appendDurationValue(g.buf, time.Duration(unpackFullNum(node.kind, node.misc)))
 
 		case prettyViewKindValueInt:
 
@@ -115,13 +104,13 @@ mainLoop:
 
 			g.buf = append(g.buf, ':', ' ')
 
-			value := math.Float64frombits(
+			num := math.Float64frombits(
 				unpackFullNum(node.kind, node.misc),
 			)
 
 			g.buf = strconv.AppendFloat(
 				g.buf,
-				value,
+				num,
 				'g',
 				-1,
 				64,
@@ -136,7 +125,7 @@ mainLoop:
 
 			off := node.kind >> 32
 
-			value := unsafe.String(
+			str := unsafe.String(
 				(*byte)(unsafe.Add(
 					unsafe.Pointer(unsafe.SliceData(t.data)),
 					off,
@@ -147,12 +136,15 @@ mainLoop:
 			if !errFound {
 				if key == "@location" {
 					g.colorLocation()
+					g.formatLocationText(str)
+					g.colorReset()
+					break
 				}
-				g.buf = append(g.buf, value...)
+				g.buf = g.appendText(g.buf, str)
 				break
 			}
 			g.colorLevelError()
-			g.buf = append(g.buf, value...)
+			g.buf = g.appendText(g.buf, str)
 			g.colorReset()
 
 		case prettyViewKindValueStringShort:
@@ -164,18 +156,19 @@ mainLoop:
 			var shortPlace uint64
 			var longPlace [16]byte
 
-			value := unpackShortStringValue(
+			data := unpackShortStringValue(
 				node,
 				&shortPlace,
 				longPlace,
 			)
+			str := unsafe.String(unsafe.SliceData(data), len(data))
 
 			if !errFound {
-				g.buf = append(g.buf, value...)
+				g.buf = g.appendText(g.buf, str)
 				break
 			}
 			g.colorLevelError()
-			g.buf = append(g.buf, value...)
+			g.buf = g.appendText(g.buf, str)
 			g.colorReset()
 
 		case prettyViewKindValueByteSlice:
@@ -184,7 +177,7 @@ mainLoop:
 
 			off := node.kind >> 32
 
-			value := unsafe.Slice(
+			data := unsafe.Slice(
 				(*byte)(unsafe.Add(
 					unsafe.Pointer(unsafe.SliceData(t.data)),
 					off,
@@ -193,7 +186,7 @@ mainLoop:
 			)
 
 			g.buf = append(g.buf, "base64."...)
-			g.buf = base64.RawStdEncoding.AppendEncode(g.buf, value)
+			g.buf = g.appendBase64(g.buf, base64.RawStdEncoding, data)
 
 		case prettyViewKindValueByteSliceShort:
 
@@ -201,17 +194,17 @@ mainLoop:
 			var shortPlace uint64
 			var longPlace [16]byte
 
-			value := unpackShortStringValue(
+			data := unpackShortStringValue(
 				node,
 				&shortPlace,
 				longPlace,
 			)
 
 			g.buf = append(g.buf, "base64."...)
-			g.buf = base64.RawStdEncoding.AppendEncode(g.buf, value)
+			g.buf = base64.RawStdEncoding.AppendEncode(g.buf, data)
 
 		case prettyViewKindValueBoolSlice:
-			g.unpackBoolsTree(node)
+			g.formatBools(node)
 
 		case prettyViewKindValueBoolSliceShort:
 			g.formatShortBool(node)
@@ -249,6 +242,11 @@ mainLoop:
 		case prettyViewKindValueStringSlice:
 			g.formatStringSlice(node, t)
 
+		case prettyViewKindValueSecret:
+
+			g.buf = append(g.buf, ':', ' ')
+			g.buf = append(g.buf, redactedMarker...)
+
 		default:
 
 			g.buf = append(g.buf, ':', ' ')
@@ -258,6 +256,10 @@ mainLoop:
 			)
 		}
 
+		// Values rendered as branches of elements start from the new line and are wrapped on their own.
+		if g.buf[value] != '\n' {
+			g.wrapLine(start, value)
+		}
 		g.buf = append(g.buf, '\n')
 
 		if node.next == 0 {
@@ -294,216 +296,127 @@ mainLoop:
 func formatIntSlice[T int8 | int16 | int32 | int64](g *PrettyWriter, node *prettyViewNode, t *packedTree) {
 	off := node.kind >> 32
 	src := unsafe.Slice((*T)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
-	length := int(node.misc)
-	switch length {
-	case 0:
-		g.buf = append(g.buf, ':', ' ', '[', ']')
-	case 1, 2, 3, 4, 5, 6, 7, 8:
-		g.buf = append(g.buf, ':', ' ')
-		for i, v := range src {
-			if i > 0 {
-				g.buf = append(g.buf, ',', ' ')
-			}
-			g.buf = strconv.AppendInt(g.buf, int64(v), 10)
-		}
-	default:
-		g.buf = append(g.buf, '\n')
-		g.branch = append(g.branch, true)
-		for i, v := range src {
-			g.drawPrefix(g.branch, i == len(src)-1, node.misc == 0)
-			g.buf = strconv.AppendInt(g.buf, int64(i), 10)
-			g.buf = append(g.buf, ':', ' ')
-			g.buf = strconv.AppendInt(g.buf, int64(v), 10)
-			g.buf = append(g.buf, '\n')
-		}
-		g.branch = g.branch[:len(g.branch)-1]
-		g.buf = g.buf[:len(g.buf)-1]
-	}
+	formatSlice(g, src, func(buf []byte, v T) []byte {
+		return strconv.AppendInt(buf, int64(v), 10)
+	})
 }
 
 func formatUintSlice[T uint8 | uint16 | uint32 | uint64](g *PrettyWriter, node *prettyViewNode, t *packedTree) {
 	off := node.kind >> 32
 	src := unsafe.Slice((*T)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
-	length := int(node.misc)
-	switch length {
-	case 0:
-		g.buf = append(g.buf, ':', ' ', '[', ']')
-	case 1, 2, 3, 4, 5, 6, 7, 8:
-		g.buf = append(g.buf, ':', ' ')
-		for i, v := range src {
-			if i > 0 {
-				g.buf = append(g.buf, ',', ' ')
-			}
-			g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
-		}
-	default:
-		g.buf = append(g.buf, '\n')
-		g.branch = append(g.branch, true)
-		for i, v := range src {
-			g.drawPrefix(g.branch, i == len(src)-1, node.misc == 0)
-			g.buf = strconv.AppendUint(g.buf, uint64(i), 10)
-			g.buf = append(g.buf, ':', ' ')
-			g.buf = strconv.AppendUint(g.buf, uint64(v), 10)
-			g.buf = append(g.buf, '\n')
-		}
-		g.branch = g.branch[:len(g.branch)-1]
-		g.buf = g.buf[:len(g.buf)-1]
-	}
+	formatSlice(g, src, func(buf []byte, v T) []byte {
+		return strconv.AppendUint(buf, uint64(v), 10)
+	})
 }
 
 func formatFloatSlice[T float32 | float64](g *PrettyWriter, node *prettyViewNode, t *packedTree, bits int) {
 	off := node.kind >> 32
 	src := unsafe.Slice((*T)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), off)), node.misc)
-	length := int(node.misc)
-	switch length {
-	case 0:
+	formatSlice(g, src, func(buf []byte, v T) []byte {
+		return strconv.AppendFloat(buf, float64(v), 'g', -1, bits)
+	})
+}
+
+func (g *PrettyWriter) formatStringSlice(node *prettyViewNode, t *packedTree) {
+	g.strs = unpackStrings(g.strs[:0], node, t)
+	formatSlice(g, g.strs, func(buf []byte, v string) []byte {
+		return g.appendQuotedText(buf, v)
+	})
+}
+
+func (g *PrettyWriter) formatShortBool(node *prettyViewNode) {
+	g.bools = unpackShortBools(g.bools[:0], node)
+	formatSlice(g, g.bools, strconv.AppendBool)
+}
+
+func (g *PrettyWriter) formatBools(node *prettyViewNode) {
+	g.bools = unpackBools(g.bools[:0], node, g.view.tree)
+	formatSlice(g, g.bools, strconv.AppendBool)
+}
+
+// formatSlice renders a slice as a value of the tree node. Short slices are rendered inline,
+// slices longer than the limit of items are elided, other ones are rendered as branches of
+// their elements.
+func formatSlice[T any](g *PrettyWriter, src []T, appendItem func([]byte, T) []byte) {
+	switch {
+	case len(src) == 0:
 		g.buf = append(g.buf, ':', ' ', '[', ']')
-	case 1, 2, 3, 4, 5, 6, 7, 8:
-		g.buf = append(g.buf, ':', ' ')
-		for i, v := range src {
+	case g.maxItems > 0 && len(src) > g.maxItems:
+		g.buf = append(g.buf, ':', ' ', '[')
+		for i, v := range src[:g.maxItems] {
 			if i > 0 {
 				g.buf = append(g.buf, ',', ' ')
 			}
-			g.buf = strconv.AppendFloat(g.buf, float64(v), 'g', -1, bits)
-		}
-	default:
-		g.buf = append(g.buf, '\n')
-		g.branch = append(g.branch, true)
-		for i, v := range src {
-			g.drawPrefix(g.branch, i == len(src)-1, node.misc == 0)
-			g.buf = strconv.AppendUint(g.buf, uint64(i), 10)
-			g.buf = append(g.buf, ':', ' ')
-			g.buf = strconv.AppendFloat(g.buf, float64(v), 'g', -1, bits)
-			g.buf = append(g.buf, '\n')
+			g.buf = appendItem(g.buf, v)
 		}
-		g.branch = g.branch[:len(g.branch)-1]
-		g.buf = g.buf[:len(g.buf)-1]
-	}
-}
-
-func (g *PrettyWriter) formatStringSlice(node *prettyViewNode, t *packedTree) {
-	src := unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), node.kind>>32)
-	length := int(node.misc)
-	switch length {
-	case 0:
-		g.buf = append(g.buf, ':', ' ', '[', ']')
-	case 1, 2, 3, 4, 5, 6, 7, 8:
+		g.buf = g.appendMoreItems(g.buf, len(src)-g.maxItems)
+		g.buf = append(g.buf, ']')
+	case len(src) <= 8:
 		g.buf = append(g.buf, ':', ' ')
-		for i := range length {
-			lens := *(*uint32)(src)
+		for i, v := range src {
 			if i > 0 {
 				g.buf = append(g.buf, ',', ' ')
 			}
-			str := unsafe.String((*byte)(unsafe.Add(src, 4)), lens)
-			g.buf = strconv.AppendQuote(g.buf, str)
-			src = unsafe.Add(src, 4+lens)
+			g.buf = appendItem(g.buf, v)
 		}
 	default:
 		g.buf = append(g.buf, '\n')
-		g.branch = append(g.branch, true)
-		for i := range length {
-			g.drawPrefix(g.branch, i == length-1, node.misc == 0)
-			g.buf = strconv.AppendUint(g.buf, uint64(i), 10)
+		for i, v := range src {
+			last := i == len(src)-1
+			start := len(g.buf)
+			g.drawPrefix(g.branch, last)
+			g.buf = strconv.AppendInt(g.buf, int64(i), 10)
+			value := len(g.buf)
 			g.buf = append(g.buf, ':', ' ')
-			lens := *(*uint32)(src)
-			str := unsafe.String((*byte)(unsafe.Add(src, 4)), lens)
-			g.buf = append(g.buf, str...)
+			g.buf = appendItem(g.buf, v)
+
+			g.branch = append(g.branch, !last)
+			g.wrapLine(start, value)
+			g.branch = g.branch[:len(g.branch)-1]
 			g.buf = append(g.buf, '\n')
-			src = unsafe.Add(src, 4+lens)
 		}
-		g.branch = g.branch[:len(g.branch)-1]
 		g.buf = g.buf[:len(g.buf)-1]
 	}
 }
 
-func (g *PrettyWriter) formatShortBool(node *prettyViewNode) {
+// unpackShortBools appends elements of the bool slice packed into the node itself.
+func unpackShortBools(dst []bool, node *prettyViewNode) []bool {
 	length := node.kind << 52 >> 57
-	if length == 0 {
-		g.buf = append(g.buf, ": []"...)
-		return
-	}
 	part1 := node.kind >> 12
 	part2 := node.misc
-	switch {
-	case length <= 8:
-		g.buf = append(g.buf, ':', ' ')
-		for range length {
-			if part1&0x01 != 0 {
-				g.buf = append(g.buf, "true, "...)
-			} else {
-				g.buf = append(g.buf, "false, "...)
-			}
-			part1 >>= 1
-		}
-		g.buf = g.buf[:len(g.buf)-2]
-	case length <= 52:
-		g.branch = append(g.branch, true)
-		g.buf = append(g.buf, '\n')
-		for i := range length {
-			g.drawPrefix(g.branch, i == length-1, node.misc == 0)
-			g.buf = strconv.AppendInt(g.buf, int64(i), 10)
-			g.buf = append(g.buf, ':', ' ')
-			if part1&0x01 != 0 {
-				g.buf = append(g.buf, "true"...)
-			} else {
-				g.buf = append(g.buf, "false"...)
-			}
-			g.buf = append(g.buf, '\n')
-			part1 >>= 1
-		}
-		g.branch = g.branch[:len(g.branch)-1]
-		g.buf = g.buf[:len(g.buf)-1]
-	default:
-		g.branch = append(g.branch, true)
-		g.buf = append(g.buf, '\n')
-		for i := range 52 {
-			g.drawPrefix(g.branch, false, node.misc == 0)
-			g.buf = strconv.AppendInt(g.buf, int64(i), 10)
-			g.buf = append(g.buf, ':', ' ')
-			if part1&0x01 != 0 {
-				g.buf = append(g.buf, "true"...)
-			} else {
-				g.buf = append(g.buf, "false"...)
-			}
-			g.buf = append(g.buf, '\n')
+	for i := range length {
+		if i < 52 {
+			dst = append(dst, part1&0x01 != 0)
 			part1 >>= 1
-		}
-		length -= 52
-		for i := range length {
-			g.drawPrefix(g.branch, i == length-1, node.misc == 0)
-			g.buf = strconv.AppendInt(g.buf, int64(i+52), 10)
-			g.buf = append(g.buf, ':', ' ')
-			if part2&0x01 != 0 {
-				g.buf = append(g.buf, "true"...)
-			} else {
-				g.buf = append(g.buf, "false"...)
-			}
-			g.buf = append(g.buf, '\n')
+		} else {
+			dst = append(dst, part2&0x01 != 0)
 			part2 >>= 1
 		}
-		g.branch = g.branch[:len(g.branch)-1]
-		g.buf = g.buf[:len(g.buf)-1]
 	}
+
+	return dst
 }
 
-func (g *PrettyWriter) drawPrefix(branch []bool, last bool, inLast bool) {
-	g.colorLink()
-	for i := 0; i < len(branch); i++ {
-		if branch[i] {
-			if !inLast {
-				g.buf = append(g.buf, "│  "...)
-			} else {
-				g.buf = append(g.buf, "   "...)
-			}
-		} else {
-			g.buf = append(g.buf, "   "...)
-		}
+// unpackStrings appends elements of the string slice, they are stored as 4 bytes lengths followed with data.
+func unpackStrings(dst []string, node *prettyViewNode, t *packedTree) []string {
+	src := unsafe.Add(unsafe.Pointer(unsafe.SliceData(t.data)), node.kind>>32)
+	for range int(node.misc) {
+		lens := *(*uint32)(src)
+		dst = append(dst, unsafe.String((*byte)(unsafe.Add(src, 4)), lens))
+		src = unsafe.Add(src, 4+lens)
 	}
 
+	return dst
+}
+
+func (g *PrettyWriter) drawPrefix(branch []bool, last bool) {
+	g.colorLink()
+	g.drawBranches(branch)
+
 	if last {
-		g.buf = append(g.buf, "└─ "...)
+		g.buf = append(g.buf, g.glyphs.last...)
 	} else {
-		g.buf = append(g.buf, "├─ "...)
+		g.buf = append(g.buf, g.glyphs.branch...)
 	}
 	g.colorReset()
 }
@@ -0,0 +1,167 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"unicode/utf8"
+)
+
+// prettyWrapMinWidth is the least width of wrapped parts of values. Values of deeply nested
+// nodes are wrapped beyond the width to keep them readable.
+const prettyWrapMinWidth = 20
+
+// wrapLine wraps the last line of the buffer starting at the start to the width set with
+// [PrettyWriter.WithWrapWidth]. Only the value part of the line starting at the value offset
+// is wrapped, its new lines are turned into wraps too. Continuation lines are prefixed
+// with the current branch.
+func (g *PrettyWriter) wrapLine(start, value int) {
+	if g.width <= 0 {
+		return
+	}
+
+	indent := 3 * len(g.branch)
+	width := max(g.width, indent+prettyWrapMinWidth)
+	if visibleWidth(g.buf[start:]) <= width && bytes.IndexByte(g.buf[value:], '\n') < 0 {
+		return
+	}
+
+	g.wrap = append(g.wrap[:0], g.buf[value:]...)
+	g.buf = g.buf[:value]
+	col := visibleWidth(g.buf[start:])
+
+	// color is the last color sequence of the value, it is restored after the prefix of a continuation.
+	var color []byte
+	for text := g.wrap; len(text) > 0; {
+		if n, isColor := escapeLength(text); n > 0 {
+			if isColor {
+				color = text[:n]
+				if string(color) == "\033[0m" {
+					color = nil
+				}
+			}
+			g.buf = append(g.buf, text[:n]...)
+			text = text[n:]
+			continue
+		}
+		if text[0] == '\n' {
+			g.wrapBreak(color)
+			col = indent
+			text = text[1:]
+			continue
+		}
+
+		if col >= width {
+			g.wrapBreak(color)
+			col = indent
+		}
+		_, size := utf8.DecodeRune(text)
+		g.buf = append(g.buf, text[:size]...)
+		text = text[size:]
+		col++
+	}
+}
+
+// wrapBreak starts a continuation line of the wrapped value.
+func (g *PrettyWriter) wrapBreak(color []byte) {
+	g.buf = append(g.buf, '\n')
+	g.colorLink()
+	g.drawBranches(g.branch)
+	g.colorReset()
+	g.buf = append(g.buf, color...)
+}
+
+// drawBranches draws vertical lines of branches which are not finished yet.
+func (g *PrettyWriter) drawBranches(branch []bool) {
+	for _, open := range branch {
+		if open {
+			g.buf = append(g.buf, g.glyphs.line...)
+		} else {
+			g.buf = append(g.buf, "   "...)
+		}
+	}
+}
+
+// elideString cuts the string to the limit of runes and returns the number of runes cut.
+// Zero limit means no limit.
+func elideString(s string, limit int) (string, int) {
+	if limit <= 0 || len(s) <= limit {
+		return s, 0
+	}
+
+	var n int
+	for i := range s {
+		if n == limit {
+			return s[:i], utf8.RuneCountInString(s[i:])
+		}
+		n++
+	}
+
+	return s, 0
+}
+
+// elideSlice cuts the slice to the limit of items and returns the number of items cut.
+// Zero limit means no limit.
+func elideSlice[T any](src []T, limit int) ([]T, int) {
+	if limit <= 0 || len(src) <= limit {
+		return src, 0
+	}
+
+	return src[:limit], len(src) - limit
+}
+
+// appendText appends the text elided to the limit set with [PrettyWriter.WithMaxStringLength].
+func (g *PrettyWriter) appendText(buf []byte, s string) []byte {
+	s, more := elideString(s, g.maxString)
+	buf = append(buf, s...)
+	return g.appendMoreText(buf, more)
+}
+
+// appendQuotedText appends the quoted text elided to the limit, the elision mark is put within quotes.
+func (g *PrettyWriter) appendQuotedText(buf []byte, s string) []byte {
+	s, more := elideString(s, g.maxString)
+	buf = strconv.AppendQuote(buf, s)
+	if more == 0 {
+		return buf
+	}
+
+	buf = g.appendMoreText(buf[:len(buf)-1], more)
+	return append(buf, '"')
+}
+
+// appendBase64 appends encoded data elided to the limit of encoded characters.
+func (g *PrettyWriter) appendBase64(buf []byte, enc *base64.Encoding, data []byte) []byte {
+	start := len(buf)
+	buf = enc.AppendEncode(buf, data)
+	if g.maxString <= 0 || len(buf)-start <= g.maxString {
+		return buf
+	}
+
+	more := len(buf) - start - g.maxString
+	return g.appendMoreText(buf[:start+g.maxString], more)
+}
+
+// appendMoreText appends a mark of the elided text, like "… (997 more)".
+func (g *PrettyWriter) appendMoreText(buf []byte, more int) []byte {
+	if more == 0 {
+		return buf
+	}
+
+	buf = append(buf, g.glyphs.ellipsis...)
+	buf = append(buf, " ("...)
+	buf = strconv.AppendInt(buf, int64(more), 10)
+	return append(buf, " more)"...)
+}
+
+// appendMoreItems appends a mark of elided items following shown ones, like ", … 997 more".
+func (g *PrettyWriter) appendMoreItems(buf []byte, more int) []byte {
+	if more == 0 {
+		return buf
+	}
+
+	buf = append(buf, ", "...)
+	buf = append(buf, g.glyphs.ellipsis...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(more), 10)
+	return append(buf, " more"...)
+}
@@ -0,0 +1,304 @@
+package blog
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/sirkon/blog/internal/core"
+)
+
+// prettyStacktrace renders a stack trace logged as a group with the @stack key back into
+// the text in the format of [runtime/debug.Stack].
+type prettyStacktrace struct {
+	// depth of groups within @stack, it is 0 when not in @stack.
+	depth int
+	text  []byte
+
+	created   bool
+	function  []byte
+	file      []byte
+	line      int
+	offset    uint64
+	goroutine int
+}
+
+func (s *prettyStacktrace) reset() {
+	s.depth = 0
+	s.text = s.text[:0]
+}
+
+func (s *prettyStacktrace) enter(key []byte) {
+	s.depth++
+	switch s.depth {
+	case 2:
+		// Goroutine, its key is a header like "goroutine 8 [running]".
+		if len(s.text) > 0 {
+			s.text = append(s.text, '\n')
+		}
+		s.text = append(s.text, key...)
+		s.text = append(s.text, ':', '\n')
+	case 3:
+		// Either frames or a creator frame.
+		s.created = string(key) == "created-by"
+		s.resetFrame()
+	case 4:
+		s.resetFrame()
+	}
+}
+
+func (s *prettyStacktrace) leave() {
+	if s.depth == 4 || s.depth == 3 && s.created {
+		s.appendFrame()
+	}
+	s.depth--
+}
+
+func (s *prettyStacktrace) str(key []byte, value []byte) {
+	switch string(key) {
+	case "func":
+		s.function = value
+	case "file":
+		s.file = value
+	}
+}
+
+func (s *prettyStacktrace) int(key []byte, value int) {
+	switch string(key) {
+	case "line":
+		s.line = value
+	case "goroutine":
+		s.goroutine = value
+	}
+}
+
+func (s *prettyStacktrace) uint(key []byte, value uint64) {
+	if string(key) == "offset" {
+		s.offset = value
+	}
+}
+
+func (s *prettyStacktrace) resetFrame() {
+	s.function = nil
+	s.file = nil
+	s.line = 0
+	s.offset = 0
+	s.goroutine = -1
+}
+
+func (s *prettyStacktrace) appendFrame() {
+	if s.created {
+		s.text = append(s.text, "created by "...)
+		s.text = append(s.text, s.function...)
+		if s.goroutine >= 0 {
+			s.text = append(s.text, " in goroutine "...)
+			s.text = strconv.AppendInt(s.text, int64(s.goroutine), 10)
+		}
+	} else {
+		s.text = append(s.text, s.function...)
+		s.text = append(s.text, "(...)"...)
+	}
+	s.text = append(s.text, '\n', '\t')
+	s.text = append(s.text, s.file...)
+	s.text = append(s.text, ':')
+	s.text = strconv.AppendInt(s.text, int64(s.line), 10)
+	if s.offset != 0 {
+		s.text = append(s.text, " +0x"...)
+		s.text = strconv.AppendUint(s.text, s.offset, 16)
+	}
+	s.text = append(s.text, '\n')
+}
+
+// formatStacktrace renders the stack trace parsed into goroutines and their frames. The frame which
+// panicked is highlighted, runs of frames of the runtime, of the standard library and of the logger
+// itself are folded unless [PrettyWriter.WithFullStacktraces] is set. Texts which are not stack traces are rendered as they are.
+func (g *PrettyWriter) formatStacktrace(text []byte) {
+	if len(text) == 0 {
+		return
+	}
+
+	goroutines := core.ParseStacktrace(text)
+	if len(goroutines) == 0 {
+		for line := range bytes.Lines(text) {
+			g.formatStacktraceLine(bytes.TrimSuffix(line, newline))
+		}
+		return
+	}
+
+	for i, gr := range goroutines {
+		if i > 0 {
+			g.formatStacktraceLine(nil)
+		}
+		g.formatStacktraceLine(append(append(g.path[:0], gr.Header...), ':'))
+
+		panicked := -1
+		if i == 0 {
+			panicked = panickedFrame(gr.Frames)
+		}
+		var folded int
+		var kinds stackFoldKind
+		// Frames of the logger are only folded on top of the stack, where they capture it.
+		leading := true
+		for j, frame := range gr.Frames {
+			kind := stackFrameFoldKind(frame.Function)
+			if kind == 0 {
+				leading = false
+			} else if kind == stackFoldLogger && !leading {
+				kind = 0
+			}
+			if !g.fullStacks && j != panicked && kind != 0 {
+				folded++
+				kinds |= kind
+				continue
+			}
+			g.formatFoldedFrames(folded, kinds)
+			folded = 0
+			kinds = 0
+			g.formatStackFrame(frame, "", j == panicked)
+		}
+		g.formatFoldedFrames(folded, kinds)
+
+		if gr.CreatedBy != nil {
+			g.path = append(g.path[:0], "created by "...)
+			g.path = append(g.path, gr.CreatedBy.Function...)
+			if gr.CreatedIn >= 0 {
+				g.path = append(g.path, " in goroutine "...)
+				g.path = strconv.AppendInt(g.path, int64(gr.CreatedIn), 10)
+			}
+			g.formatStackFrame(*gr.CreatedBy, unsafe.String(unsafe.SliceData(g.path), len(g.path)), false)
+		}
+	}
+}
+
+// formatStackFrame renders the frame as two lines: the function and its location. The title replaces
+// the function name if it is not empty.
+func (g *PrettyWriter) formatStackFrame(frame core.StackFrame, title string, panicked bool) {
+	if title == "" {
+		title = frame.Function
+	}
+
+	g.colorSTDots()
+	g.buf = append(g.buf, '.', '.', '.', '.', ' ')
+	if panicked {
+		g.colorLevelPanic()
+		g.colorBold()
+		g.buf = append(g.buf, g.glyphs.panicked...)
+	} else {
+		g.colorSTText()
+	}
+	g.buf = append(g.buf, title...)
+	g.colorReset()
+	g.buf = append(g.buf, '\n')
+
+	g.colorSTDots()
+	g.buf = append(g.buf, '.', '.', '.', '.', ' ', '\t')
+	g.colorLocation()
+	g.formatPath(frame.File, frame.Line)
+	g.colorReset()
+	if frame.Offset != 0 && !g.determined {
+		g.colorSTText()
+		g.buf = append(g.buf, " +0x"...)
+		g.buf = strconv.AppendUint(g.buf, frame.Offset, 16)
+		g.colorReset()
+	}
+	g.buf = append(g.buf, '\n')
+}
+
+// formatFoldedFrames renders a mark of folded frames of the given kinds.
+func (g *PrettyWriter) formatFoldedFrames(n int, kinds stackFoldKind) {
+	if n == 0 {
+		return
+	}
+
+	g.colorSTDots()
+	g.buf = append(g.buf, '.', '.', '.', '.', ' ')
+	g.buf = append(g.buf, g.glyphs.ellipsis...)
+	g.buf = append(g.buf, ' ')
+	g.buf = strconv.AppendInt(g.buf, int64(n), 10)
+	switch kinds {
+	case stackFoldStd:
+		g.buf = append(g.buf, " runtime/stdlib frame"...)
+	case stackFoldLogger:
+		g.buf = append(g.buf, " logger frame"...)
+	default:
+		g.buf = append(g.buf, " runtime/stdlib/logger frame"...)
+	}
+	if n > 1 {
+		g.buf = append(g.buf, 's')
+	}
+	g.colorReset()
+	g.buf = append(g.buf, '\n')
+}
+
+// panickedFrame returns the index of the frame which panicked: the first frame following the last
+// call of panic which is not a frame of the runtime. Runtime frames there are like runtime.sigpanic
+// or runtime.panicIndex, for panics raised by the runtime itself. Returns -1 if there is no panic call.
+func panickedFrame(frames []core.StackFrame) int {
+	res := -1
+	for i, frame := range frames {
+		if frame.Function == "panic" || frame.Function == "runtime.gopanic" {
+			res = -1
+			for j := i + 1; j < len(frames); j++ {
+				if !strings.HasPrefix(frames[j].Function, "runtime.") {
+					res = j
+					break
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// stackFoldKind is a bit set of kinds of folded frames.
+type stackFoldKind uint8
+
+const (
+	stackFoldStd stackFoldKind = 1 << iota
+	stackFoldLogger
+)
+
+// loggerPackages are packages of the logger whose frames are folded.
+var loggerPackages = []string{
+	"github.com/sirkon/blog",
+	"github.com/sirkon/blog/internal/core",
+}
+
+// stackFrameFoldKind returns the kind of the frame of the function if it is to be folded or 0 otherwise.
+func stackFrameFoldKind(function string) stackFoldKind {
+	switch {
+	case isStdFunction(function):
+		return stackFoldStd
+	case isLoggerFunction(function):
+		return stackFoldLogger
+	default:
+		return 0
+	}
+}
+
+// isLoggerFunction tells if the function belongs to the logger itself. Stacks logged have frames
+// of the stack capture and of the recovery, like core.LogRecovered or blog.Recover, on top.
+func isLoggerFunction(function string) bool {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return false
+	}
+
+	return slices.Contains(loggerPackages, function[:slash+1+dot])
+}
+
+// isStdFunction tells if the function belongs to the runtime or to the standard library. Function names
+// look like net/http.(*conn).serve or github.com/user/project.Func, the first element of paths
+// of standard packages has no dots.
+func isStdFunction(function string) bool {
+	first, _, nested := strings.Cut(function, "/")
+	if nested {
+		return !strings.Contains(first, ".")
+	}
+
+	pkg, _, _ := strings.Cut(function, ".")
+	return pkg != "main"
+}
@@ -0,0 +1,144 @@
+package blog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// terminalQueryTimeout limits waiting for the terminal to report its background.
+const terminalQueryTimeout = 100 * time.Millisecond
+
+// WithAutoColors turns on colors when they are appropriate for the writer:
+//
+//   - Colors are off when NO_COLOR environment variable is set or TERM is dumb.
+//   - Colors are on when CLICOLOR_FORCE is set to anything but 0.
+//   - Otherwise, colors are on when the writer is a terminal.
+//
+// The theme is taken from [ThemeFromEnv] if set. Otherwise, it is either [ThemeDark] or [ThemeLight]
+// depending on the background of the terminal, which is taken from COLORFGBG environment variable
+// or queried from the terminal itself. The color depth is guessed with [DetectColorDepth].
+func (g *PrettyWriter) WithAutoColors() *PrettyWriter {
+	terminal := isTerminal(g.w)
+	if !colorsEnabled(terminal) {
+		return g
+	}
+
+	theme, err := ThemeFromEnv()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "failed to get a theme from the environment:", err)
+	}
+	if theme == nil {
+		theme = ThemeDark()
+		if terminalBackgroundIsLight(terminal) {
+			theme = ThemeLight()
+		}
+	}
+
+	return g.WithTheme(theme, DetectColorDepth())
+}
+
+// WithTerminalWidth wraps trees to the width of the terminal and renders contexts inline with
+// [PrettyLayoutAuto] only when they fit into it. The width is taken from the terminal if the writer
+// is one, or from COLUMNS environment variable otherwise. Does nothing if neither is available.
+func (g *PrettyWriter) WithTerminalWidth() *PrettyWriter {
+	width := terminalWidth(g.w)
+	if width <= 0 {
+		return g
+	}
+
+	return g.WithWrapWidth(width).WithInlineWidth(width)
+}
+
+func colorsEnabled(terminal bool) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+
+	return terminal
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok && isTerminal(f) {
+		if width := terminalColumns(f.Fd()); width > 0 {
+			return width
+		}
+	}
+
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil {
+		return 0
+	}
+
+	return width
+}
+
+// terminalBackgroundIsLight checks COLORFGBG first and asks the terminal then, if the output is a terminal.
+func terminalBackgroundIsLight(terminal bool) bool {
+	if fgbg := os.Getenv("COLORFGBG"); fgbg != "" {
+		// Like "15;0", where the last number is the color of the background.
+		bg := fgbg[strings.LastIndexByte(fgbg, ';')+1:]
+		if index, err := strconv.Atoi(bg); err == nil {
+			return index == 7 || index >= 9
+		}
+	}
+	if !terminal {
+		return false
+	}
+
+	r, g, b, ok := queryTerminalBackground(terminalQueryTimeout)
+	if !ok {
+		return false
+	}
+
+	// Relative luminance of 16-bit components.
+	luminance := 0.2126*float64(r) + 0.7152*float64(g) + 0.0722*float64(b)
+	return luminance > 0.5*0xffff
+}
+
+// parseTerminalColor parses a color reported by the terminal in OSC 11 response, like
+// "\033]11;rgb:ffff/ffff/dddd\033\\". Components may have 1 to 4 hex digits.
+func parseTerminalColor(response string) (r, g, b uint16, ok bool) {
+	_, spec, found := strings.Cut(response, "rgb:")
+	if !found {
+		return 0, 0, 0, false
+	}
+	spec = strings.TrimRight(spec, "\a\033\\")
+
+	parts := strings.Split(spec, "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+
+	var res [3]uint16
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 4 {
+			return 0, 0, 0, false
+		}
+		v, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		// Scale to 16 bits: "f" is 0xffff just like "ffff" is.
+		res[i] = uint16(v * 0xffff / (1<<(4*len(part)) - 1))
+	}
+
+	return res[0], res[1], res[2], true
+}
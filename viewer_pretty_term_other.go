@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package blog
+
+import "time"
+
+// queryTerminalBackground is not supported here.
+func queryTerminalBackground(timeout time.Duration) (r, g, b uint16, ok bool) {
+	return 0, 0, 0, false
+}
+
+// terminalColumns is not supported here.
+func terminalColumns(fd uintptr) int {
+	return 0
+}
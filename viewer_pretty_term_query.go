@@ -0,0 +1,61 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package blog
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// queryTerminalBackground asks the controlling terminal for its background color with OSC 11.
+// The terminal is switched to the non-canonical mode for a while, so the response is neither
+// echoed nor waits for a new line. Terminals not supporting the query just do not respond.
+func queryTerminalBackground(timeout time.Duration) (r, g, b uint16, ok bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	defer func() {
+		_ = tty.Close()
+	}()
+
+	fd := int(tty.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	raw := *state
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	// Reads return after VTIME deciseconds without input.
+	raw.Cc[unix.VMIN] = 0
+	raw.Cc[unix.VTIME] = uint8(max(timeout/(100*time.Millisecond), 1))
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return 0, 0, 0, false
+	}
+	defer func() {
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, state)
+	}()
+
+	if _, err := tty.WriteString("\033]11;?\033\\"); err != nil {
+		return 0, 0, 0, false
+	}
+
+	var response []byte
+	buf := make([]byte, 64)
+	for len(response) < 256 {
+		n, err := tty.Read(buf)
+		if n <= 0 || err != nil {
+			break
+		}
+		response = append(response, buf[:n]...)
+		// The response is terminated either with BEL or ST.
+		if bytes.IndexByte(response, '\a') >= 0 || bytes.Contains(response, []byte("\033\\")) {
+			break
+		}
+	}
+
+	return parseTerminalColor(string(response))
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package blog
+
+import "golang.org/x/sys/unix"
+
+// terminalColumns returns the number of columns of the terminal.
+func terminalColumns(fd uintptr) int {
+	size, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+
+	return int(size.Col)
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -254,3 +255,375 @@ func TestNewPrettyWriter(t *testing.T) {
 
 	assert.Equal(t, *sample, got)
 }
+
+func TestPrettyWriterTimeFormat(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	start := time.Date(2026, 10, 19, 11, 13, 29, 5_042_000, moscow)
+
+	type step struct {
+		time time.Time
+		want string
+	}
+	tests := []struct {
+		name  string
+		setup func(g *PrettyWriter) *PrettyWriter
+		steps []step
+	}{
+		{
+			name:  "date-time",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g },
+			steps: []step{{start.In(time.Local), start.In(time.Local).Format("2006-01-02 15:04:05") + ".005"}},
+		},
+		{
+			name: "rfc3339-utc-micro",
+			setup: func(g *PrettyWriter) *PrettyWriter {
+				return g.WithTimeFormat(PrettyTimeRFC3339).WithTimeUTC().WithTimeMicroseconds()
+			},
+			steps: []step{{start, "2026-10-19T08:13:29.005042Z"}},
+		},
+		{
+			name: "relative",
+			setup: func(g *PrettyWriter) *PrettyWriter {
+				return g.WithTimeFormat(PrettyTimeRelative)
+			},
+			steps: []step{
+				{start, "+00:00:00.000"},
+				{start.Add(time.Second), "+00:00:01.000"},
+				{start.Add(time.Hour + 2*time.Minute + 3*time.Second + 40*time.Millisecond), "+01:02:03.040"},
+			},
+		},
+		{
+			name: "delta",
+			setup: func(g *PrettyWriter) *PrettyWriter {
+				return g.WithTimeFormat(PrettyTimeDelta).WithTimeMicroseconds()
+			},
+			steps: []step{
+				{start, "+00:00:00.000000"},
+				{start.Add(15 * time.Microsecond), "+00:00:00.000015"},
+				{start.Add(10 * time.Microsecond), "-00:00:00.000005"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup(NewPrettyWriter(io.Discard))
+			for _, s := range tt.steps {
+				g.buf = g.buf[:0]
+				g.view.time = s.time
+				g.formatTime()
+				assert.Equal(t, s.want, string(g.buf))
+			}
+		})
+	}
+}
+
+func TestPrettyWriterLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(g *PrettyWriter) *PrettyWriter
+		attrs  []Attr
+		inline bool
+	}{
+		{
+			name:   "auto",
+			setup:  func(g *PrettyWriter) *PrettyWriter { return g },
+			attrs:  []Attr{Str("key", "value")},
+			inline: true,
+		},
+		{
+			name:   "auto-error",
+			setup:  func(g *PrettyWriter) *PrettyWriter { return g },
+			attrs:  []Attr{Err(io.EOF)},
+			inline: false,
+		},
+		{
+			name:   "auto-too-wide",
+			setup:  func(g *PrettyWriter) *PrettyWriter { return g.WithInlineWidth(40) },
+			attrs:  []Attr{Str("key", "value"), Str("long-key", "a rather long value")},
+			inline: false,
+		},
+		{
+			name:   "tree",
+			setup:  func(g *PrettyWriter) *PrettyWriter { return g.WithLayout(PrettyLayoutTree) },
+			attrs:  []Attr{Str("key", "value")},
+			inline: false,
+		},
+		{
+			name:   "inline-error",
+			setup:  func(g *PrettyWriter) *PrettyWriter { return g.WithLayout(PrettyLayoutInline) },
+			attrs:  []Attr{Err(io.EOF)},
+			inline: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			logger, err := NewLogger(tt.setup(NewPrettyWriter(&buf)))
+			assert.NoError(t, err)
+
+			logger.Info(context.Background(), "message", tt.attrs...)
+			lines := strings.Count(buf.String(), "\n")
+			if tt.inline {
+				assert.Equal(t, 1, lines, buf.String())
+			} else {
+				assert.Equal(t, 1+len(tt.attrs), lines, buf.String())
+			}
+		})
+	}
+}
+
+func TestPrettyWriterWrap(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewPrettyWriter(&buf).WithLayout(PrettyLayoutTree).WithWrapWidth(30))
+	assert.NoError(t, err)
+
+	logger.Info(
+		context.Background(),
+		"message",
+		Group("request", Str("body", strings.Repeat("abcdefghij", 4)), Int("id", 1)),
+		Str("text", "first line\nsecond line"),
+	)
+	_, tree, _ := strings.Cut(buf.String(), "\n")
+	assert.Equal(t, strings.Join([]string{
+		"├─ request",
+		"│  ├─ body: abcdefghijabcdefgh",
+		"│  │  ijabcdefghijabcdefghij",
+		"│  └─ id: 1",
+		"└─ text: first line",
+		"   second line",
+		"",
+	}, "\n"), tree)
+}
+
+func TestPrettyWriterElision(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewPrettyWriter(&buf).WithMaxSliceItems(3).WithMaxStringLength(5))
+	assert.NoError(t, err)
+
+	logger.Info(
+		context.Background(),
+		"message",
+		Str("short", "value"),
+		Str("long", "long value"),
+		Ints("ids", make([]int, 1000)),
+		Bools("flags", []bool{true, false, true, true}),
+		Bytes("data", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}),
+	)
+	assert.Contains(t, buf.String(), `"short": "value", "long": "long … (5 more)"`)
+	assert.Contains(t, buf.String(), `"ids": [0, 0, 0, … 997 more]`)
+	assert.Contains(t, buf.String(), `"flags": [true, false, true, … 1 more]`)
+	assert.Contains(t, buf.String(), `"data": "AAECA… (11 more)"`)
+
+	buf.Reset()
+	logger, err = NewLogger(NewPrettyWriter(&buf).WithLayout(PrettyLayoutTree).WithMaxSliceItems(3))
+	assert.NoError(t, err)
+	logger.Info(context.Background(), "message", Ints("ids", make([]int, 1000)))
+	assert.Contains(t, buf.String(), "└─ ids: [0, 0, 0, … 997 more]\n")
+}
+
+func TestPrettyWriterLocations(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	var buf strings.Builder
+	w := NewPrettyWriter(&buf).
+		WithPathMapping(wd, "/checkout/blog").
+		WithModuleRoot("/checkout/blog").
+		WithLinks(LinkVSCode)
+	logger, err := NewLogger(w, OptionLogLocations())
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "message")
+	assert.Contains(
+		t,
+		buf.String(),
+		"\033]8;;vscode://file/checkout/blog/viewer_pretty_test.go:",
+	)
+	assert.Contains(t, buf.String(), "\033\\./viewer_pretty_test.go:")
+
+	tests := []struct {
+		name  string
+		setup func(g *PrettyWriter) *PrettyWriter
+		text  string
+		want  string
+	}{
+		{
+			name:  "as-is",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g },
+			text:  "/src/main.go:12",
+			want:  "/src/main.go:12",
+		},
+		{
+			name:  "module-cache",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g.WithModuleCachePaths() },
+			text:  "/home/user/go/pkg/mod/github.com/!burnt!sushi/toml@v1.2.0/decode.go:12",
+			want:  "github.com/BurntSushi/toml@v1.2.0/decode.go:12",
+		},
+		{
+			name:  "outside-root",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g.WithModuleRoot("/src/blog") },
+			text:  "/src/blogger/main.go:12",
+			want:  "/src/blogger/main.go:12",
+		},
+		{
+			name:  "file-link",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g.WithLinks(LinkFile) },
+			text:  "/my src/main.go:12",
+			want:  "\033]8;;file:///my%20src/main.go\033\\/my src/main.go:12\033]8;;\033\\",
+		},
+		{
+			name:  "relative-no-link",
+			setup: func(g *PrettyWriter) *PrettyWriter { return g.WithLinks(LinkIDEA) },
+			text:  "./main.go:12",
+			want:  "./main.go:12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup(NewPrettyWriter(io.Discard))
+			g.formatLocationText(tt.text)
+			assert.Equal(t, tt.want, string(g.buf))
+		})
+	}
+}
+
+func TestPrettyWriterPlain(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(
+		NewPrettyWriter(&buf).
+			WithDarkTerminal().
+			WithLinks(LinkFile).
+			WithPlain().
+			WithLayout(PrettyLayoutTree).
+			WithMaxSliceItems(2),
+		OptionLogLocations(),
+	)
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "message", Group("request", Int("id", 1), Ints("ids", []int{1, 2, 3})), Str("path", "/"))
+	_, tree, _ := strings.Cut(buf.String(), "\n")
+	assert.Equal(t, strings.Join([]string{
+		"|- request",
+		"|  |- id: 1",
+		"|  `- ids: [1, 2, ... 1 more]",
+		"`- path: /",
+		"",
+	}, "\n"), tree)
+	assert.NotContains(t, buf.String(), "\033")
+}
+
+const prettyTestPanicStacktrace = `goroutine 8 [running]:
+runtime/debug.Stack()
+	/go/src/runtime/debug/stack.go:26 +0x5e
+main.handle.func1()
+	/src/main.go:20 +0x25
+panic({0x4a5b20?, 0x5c1f30?})
+	/go/src/runtime/panic.go:792 +0x132
+runtime.goPanicIndex(0x3, 0x1)
+	/go/src/runtime/panic.go:115 +0x74
+main.handle({0x0, 0x0, 0x0})
+	/src/main.go:24 +0x66
+net/http.HandlerFunc.ServeHTTP(0x0?, {0x5c2a28?, 0xc000136000?}, 0x0?)
+	/go/src/net/http/server.go:2294 +0x29
+net/http.(*conn).serve(0xc000122000, {0x5c2f10, 0xc000110000})
+	/go/src/net/http/server.go:2092 +0x5f4
+created by net/http.(*Server).Serve in goroutine 1
+	/go/src/net/http/server.go:3360 +0x485
+`
+
+func TestPrettyWriterPanic(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewPrettyWriter(&buf).WithPlain().WithLayout(PrettyLayoutTree))
+	assert.NoError(t, err)
+	logger = logger.With(Str("service", "api"))
+
+	core.LogPanic(context.Background(), logger, []byte(prettyTestPanicStacktrace), core.LogPanicInfo("index out of range"))
+	_, out, _ := strings.Cut(buf.String(), "\n")
+	assert.Equal(t, strings.Join([]string{
+		"|- recovered: index out of range",
+		"`- service: api",
+		".... goroutine 8 [running]:",
+		".... ... 1 runtime/stdlib frame",
+		".... main.handle.func1",
+		".... \t/src/main.go:20 +0x25",
+		".... ... 2 runtime/stdlib frames",
+		".... > main.handle",
+		".... \t/src/main.go:24 +0x66",
+		".... ... 2 runtime/stdlib frames",
+		".... created by net/http.(*Server).Serve in goroutine 1",
+		".... \t/go/src/net/http/server.go:3360 +0x485",
+		"",
+	}, "\n"), out)
+
+	buf.Reset()
+	logger, err = NewLogger(NewPrettyWriter(&buf).WithFullStacktraces())
+	assert.NoError(t, err)
+	core.LogPanic(context.Background(), logger, []byte(prettyTestPanicStacktrace), core.LogPanicInfo("index out of range"))
+	assert.Contains(t, buf.String(), ".... runtime.goPanicIndex\n")
+	assert.Contains(t, buf.String(), ".... ▶ main.handle\n")
+	assert.NotContains(t, buf.String(), "frames")
+}
+
+func TestPrettyWriterDeterministic(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewPrettyWriter(&buf).WithDarkTerminal().WithDeterministic(), OptionLogLocations())
+	assert.NoError(t, err)
+
+	logger.Info(context.Background(), "first", Duration("took", 1500*time.Millisecond), Time("at", time.Now()))
+	logger.Info(context.Background(), "second")
+	core.LogPanic(context.Background(), logger, []byte(prettyTestPanicStacktrace), core.LogPanicInfo("boom"))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, `#000001 INFO  viewer_pretty_test.go:575 first  {"took": "<duration>", "at": "<time>"}`, lines[0])
+	assert.Equal(t, `#000002 INFO  viewer_pretty_test.go:576 second  {}`, lines[1])
+	assert.Contains(t, buf.String(), ".... \tmain.go:24\n")
+	assert.NotContains(t, buf.String(), "\033")
+}
+
+func TestPrettyWriterRecoveredPanic(t *testing.T) {
+	var buf strings.Builder
+	logger, err := NewLogger(NewPrettyWriter(&buf).WithPlain())
+	assert.NoError(t, err)
+
+	func() {
+		defer Recover(context.Background(), logger)
+		var m map[string]int
+		m["key"]++
+	}()
+
+	out := buf.String()
+	assert.Contains(t, out, ".... ... 6 runtime/stdlib/logger frames\n.... > github.com/sirkon/blog.TestPrettyWriterRecoveredPanic.func1\n")
+	assert.NotContains(t, out, "LogRecovered")
+	assert.NotContains(t, out, "blog.Recover")
+
+	// Legacy records have gzipped stack traces in messages.
+	buf.Reset()
+	_, err = NewPrettyWriter(&buf).WithPlain().Write(legacyPanicRecord(prettyTestPanicStacktrace))
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), ".... goroutine 8 [running]:\n")
+	assert.Contains(t, buf.String(), ".... > main.handle\n")
+}
+
+func TestPrettyWriterDeterministicOrder(t *testing.T) {
+	for name, w := range map[string]func(w io.Writer) *PrettyWriter{
+		"before": func(w io.Writer) *PrettyWriter {
+			return NewPrettyWriter(w).WithDeterministic().WithDarkTerminal().WithTimeFormat(PrettyTimeRFC3339).WithLinks(LinkVSCode)
+		},
+		"after": func(w io.Writer) *PrettyWriter {
+			return NewPrettyWriter(w).WithDarkTerminal().WithTimeFormat(PrettyTimeRFC3339).WithLinks(LinkVSCode).WithDeterministic()
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf strings.Builder
+			logger, err := NewLogger(w(&buf), OptionLogLocations())
+			assert.NoError(t, err)
+
+			logger.Info(context.Background(), "first")
+			assert.True(t, strings.HasPrefix(buf.String(), "#000001 INFO  viewer_pretty_test.go:"), buf.String())
+			assert.NotContains(t, buf.String(), "\033")
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package blog
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Theme defines styles of parts of records rendered by the [PrettyWriter], see [PrettyWriter.WithTheme].
+//
+// Themes can be described with a text of "name: style" entries separated with new lines or semicolons,
+// see [ParseTheme]:
+//
+//	base: light
+//	key: #0087af
+//	panic: bold bright-white on red
+type Theme struct {
+	Time      Style
+	Message   Style
+	Trace     Style
+	Debug     Style
+	Info      Style
+	Warn      Style
+	Error     Style
+	Panic     Style
+	Fatal     Style
+	Location  Style // Locations of records and @location of errors.
+	Link      Style // Hierarchy links of trees.
+	StackDots Style
+	StackText Style
+	Key       Style
+	ErrorKey  Style
+	Context   Style
+	Sources   []Style // Aliases of logs merged with the [MergeReader], used in turn.
+}
+
+// ThemeDark returns a theme for terminals with dark backgrounds.
+func ThemeDark() *Theme {
+	return &Theme{
+		Time:      Style{Fg: ColorBasic(5)},
+		Message:   Style{Bold: true},
+		Trace:     Style{Fg: ColorBasic(8)},
+		Debug:     Style{Fg: ColorBasic(6)},
+		Info:      Style{Fg: ColorBasic(2)},
+		Warn:      Style{Fg: ColorBasic(3)},
+		Error:     Style{Fg: ColorBasic(1)},
+		Panic:     Style{Fg: ColorBasic(15), Bg: ColorBasic(1), Bold: true},
+		Fatal:     Style{Fg: ColorBasic(15), Bg: ColorBasic(5), Bold: true},
+		Location:  Style{Fg: ColorIndexed(244)},
+		Link:      Style{Fg: ColorIndexed(240)},
+		StackDots: Style{Fg: ColorIndexed(236)},
+		StackText: Style{Fg: ColorIndexed(245)},
+		Key:       Style{Fg: ColorIndexed(109)},
+		ErrorKey:  Style{Fg: ColorIndexed(203)},
+		Context:   Style{Fg: ColorIndexed(252)},
+		Sources: []Style{
+			{Fg: ColorIndexed(75)},
+			{Fg: ColorIndexed(141)},
+			{Fg: ColorIndexed(179)},
+			{Fg: ColorIndexed(114)},
+			{Fg: ColorIndexed(204)},
+			{Fg: ColorIndexed(80)},
+		},
+	}
+}
+
+// ThemeLight returns a theme for terminals with light backgrounds.
+func ThemeLight() *Theme {
+	return &Theme{
+		Time:      Style{Fg: ColorBasic(13)},
+		Message:   Style{Bold: true},
+		Trace:     Style{Fg: ColorBasic(8)},
+		Debug:     Style{Fg: ColorBasic(6)},
+		Info:      Style{Fg: ColorBasic(2)},
+		Warn:      Style{Fg: ColorBasic(3)},
+		Error:     Style{Fg: ColorBasic(1)},
+		Panic:     Style{Fg: ColorBasic(15), Bg: ColorBasic(1), Bold: true},
+		Fatal:     Style{Fg: ColorBasic(15), Bg: ColorBasic(5), Bold: true},
+		Location:  Style{Fg: ColorIndexed(240)},
+		Link:      Style{Fg: ColorIndexed(248)},
+		StackDots: Style{Fg: ColorIndexed(252)},
+		StackText: Style{Fg: ColorIndexed(240)},
+		Key:       Style{Fg: ColorIndexed(31)},
+		ErrorKey:  Style{Fg: ColorIndexed(203)},
+		Context:   Style{Fg: ColorIndexed(238)},
+		Sources: []Style{
+			{Fg: ColorIndexed(25)},
+			{Fg: ColorIndexed(91)},
+			{Fg: ColorIndexed(130)},
+			{Fg: ColorIndexed(28)},
+			{Fg: ColorIndexed(161)},
+			{Fg: ColorIndexed(30)},
+		},
+	}
+}
+
+// ParseTheme parses a theme description. It consists of "name: style" entries separated
+// with new lines or semicolons, lines starting with # are comments. The "base" entry with
+// either "dark" or "light" value selects a theme to start from, it is the dark one by default.
+//
+// Names are time, message, trace, debug, info, warn, error, panic, fatal, location, link,
+// stack-dots, stack-text, key, error-key and context, source-1, source-2 and so on are styles of
+// aliases of merged logs. Styles are described in [ParseStyle].
+func ParseTheme(text string) (*Theme, error) {
+	type entry struct {
+		name  string
+		value string
+	}
+	var entries []entry
+	res := ThemeDark()
+	for line := range strings.Lines(text) {
+		for item := range strings.SplitSeq(line, ";") {
+			item = strings.TrimSpace(item)
+			if item == "" || strings.HasPrefix(item, "#") {
+				continue
+			}
+
+			name, value, ok := strings.Cut(item, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid entry %q, must be name: style", item)
+			}
+			name = strings.TrimSpace(name)
+			value = strings.TrimSpace(value)
+			if name != "base" {
+				entries = append(entries, entry{name: name, value: value})
+				continue
+			}
+
+			switch value {
+			case "dark":
+				res = ThemeDark()
+			case "light":
+				res = ThemeLight()
+			default:
+				return nil, fmt.Errorf("unknown base theme %q", value)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		style, err := ParseStyle(e.value)
+		if err != nil {
+			return nil, fmt.Errorf("parse style of %s: %w", e.name, err)
+		}
+		place := res.style(e.name)
+		if place == nil {
+			return nil, fmt.Errorf("unknown theme entry %q", e.name)
+		}
+		*place = style
+	}
+
+	return res, nil
+}
+
+// LoadTheme loads a theme from the file, see [ParseTheme] for its format.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read theme: %w", err)
+	}
+
+	return ParseTheme(string(data))
+}
+
+const (
+	// ThemeEnv is an environment variable with a theme description, see [ParseTheme].
+	ThemeEnv = "BLOG_THEME"
+	// ThemeFileEnv is an environment variable with a path of the theme file, see [LoadTheme].
+	ThemeFileEnv = "BLOG_THEME_FILE"
+)
+
+// ThemeFromEnv returns a theme set with [ThemeEnv] or [ThemeFileEnv] environment variables.
+// The first one takes precedence. Returns nil theme if neither is set.
+func ThemeFromEnv() (*Theme, error) {
+	if text := os.Getenv(ThemeEnv); text != "" {
+		return ParseTheme(text)
+	}
+	if path := os.Getenv(ThemeFileEnv); path != "" {
+		return LoadTheme(path)
+	}
+
+	return nil, nil
+}
+
+func (t *Theme) style(name string) *Style {
+	switch name {
+	case "time":
+		return &t.Time
+	case "message":
+		return &t.Message
+	case "trace":
+		return &t.Trace
+	case "debug":
+		return &t.Debug
+	case "info":
+		return &t.Info
+	case "warn":
+		return &t.Warn
+	case "error":
+		return &t.Error
+	case "panic":
+		return &t.Panic
+	case "fatal":
+		return &t.Fatal
+	case "location":
+		return &t.Location
+	case "link":
+		return &t.Link
+	case "stack-dots":
+		return &t.StackDots
+	case "stack-text":
+		return &t.StackText
+	case "key":
+		return &t.Key
+	case "error-key":
+		return &t.ErrorKey
+	case "context":
+		return &t.Context
+	}
+
+	num, ok := strings.CutPrefix(name, "source-")
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || n > 256 {
+		return nil
+	}
+	if n > len(t.Sources) {
+		t.Sources = append(t.Sources, make([]Style, n-len(t.Sources))...)
+	}
+	return &t.Sources[n-1]
+}
+
+// profile renders escape sequences of the theme for the color depth.
+func (t *Theme) profile(depth ColorDepth) *prettyWriterColorProfile {
+	var sources []string
+	for _, s := range t.Sources {
+		sources = append(sources, s.escape(depth))
+	}
+
+	return &prettyWriterColorProfile{
+		reset:  "\033[0m",
+		bold:   t.Message.escape(depth),
+		time:   t.Time.escape(depth),
+		trace:  t.Trace.escape(depth),
+		debug:  t.Debug.escape(depth),
+		info:   t.Info.escape(depth),
+		warn:   t.Warn.escape(depth),
+		error:  t.Error.escape(depth),
+		panic:  t.Panic.escape(depth),
+		fatal:  t.Fatal.escape(depth),
+		loc:    t.Location.escape(depth),
+		link:   t.Link.escape(depth),
+		stdots: t.StackDots.escape(depth),
+		sttext: t.StackText.escape(depth),
+		key:    t.Key.escape(depth),
+		errkey: t.ErrorKey.escape(depth),
+		ctx:    t.Context.escape(depth),
+		source: sources,
+	}
+}
+
+// Style is a style of a part of records in the [Theme].
+type Style struct {
+	Fg        Color
+	Bg        Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+}
+
+// ParseStyle parses a style made of space separated attributes and colors, like "bold red on #303030".
+//
+// Attributes are bold, faint, italic and underline. Colors are either names of basic ones: black, red,
+// green, yellow, blue, magenta, cyan and white, with the bright- prefix for their bright variants,
+// or indices of the 256 colors palette, or #rrggbb for true colors. A color following "on" is a background.
+func ParseStyle(text string) (Style, error) {
+	var res Style
+	var background bool
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		switch word {
+		case "bold":
+			res.Bold = true
+			continue
+		case "faint":
+			res.Faint = true
+			continue
+		case "italic":
+			res.Italic = true
+			continue
+		case "underline":
+			res.Underline = true
+			continue
+		case "on":
+			background = true
+			continue
+		}
+
+		c, err := parseColor(word)
+		if err != nil {
+			return Style{}, err
+		}
+		if background {
+			res.Bg = c
+		} else {
+			res.Fg = c
+		}
+	}
+
+	return res, nil
+}
+
+func (s Style) escape(depth ColorDepth) string {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Faint {
+		params = append(params, "2")
+	}
+	if s.Italic {
+		params = append(params, "3")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if p := s.Fg.param(depth, false); p != "" {
+		params = append(params, p)
+	}
+	if p := s.Bg.param(depth, true); p != "" {
+		params = append(params, p)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// ColorDepth is a number of colors a terminal supports.
+type ColorDepth int
+
+const (
+	// ColorDepth16 is for terminals supporting basic colors only.
+	ColorDepth16 ColorDepth = iota
+	// ColorDepth256 is for terminals supporting the 256 colors palette.
+	ColorDepth256
+	// ColorDepthTrue is for terminals supporting 24-bit colors.
+	ColorDepthTrue
+)
+
+// DetectColorDepth guesses the color depth of the terminal with COLORTERM and TERM environment variables.
+func DetectColorDepth() ColorDepth {
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColorDepthTrue
+	}
+	if strings.Contains(os.Getenv("TERM"), "256") {
+		return ColorDepth256
+	}
+
+	return ColorDepth16
+}
+
+type colorKind uint8
+
+const (
+	colorKindDefault colorKind = iota
+	colorKindBasic
+	colorKindIndexed
+	colorKindRGB
+)
+
+// Color is a color of the [Style]. The zero value is the default color of the terminal.
+// Colors are degraded to ones the terminal supports, see [ColorDepth].
+type Color struct {
+	kind    colorKind
+	index   uint8
+	r, g, b uint8
+}
+
+// ColorBasic returns one of 16 basic colors, 8…15 are bright variants of 0…7.
+func ColorBasic(index uint8) Color {
+	return Color{kind: colorKindBasic, index: index & 0x0f}
+}
+
+// ColorIndexed returns a color of the 256 colors palette.
+func ColorIndexed(index uint8) Color {
+	return Color{kind: colorKindIndexed, index: index}
+}
+
+// ColorRGB returns a true color.
+func ColorRGB(r, g, b uint8) Color {
+	return Color{kind: colorKindRGB, r: r, g: g, b: b}
+}
+
+var colorBasicNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+func parseColor(word string) (Color, error) {
+	if hex, ok := strings.CutPrefix(word, "#"); ok {
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return Color{}, fmt.Errorf("invalid true color %q, must be #rrggbb", word)
+		}
+		return ColorRGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+	}
+	if v, err := strconv.ParseUint(word, 10, 8); err == nil {
+		return ColorIndexed(uint8(v)), nil
+	}
+
+	name, bright := strings.CutPrefix(word, "bright-")
+	switch name {
+	case "default":
+		if !bright {
+			return Color{}, nil
+		}
+	case "gray", "grey":
+		return ColorBasic(8), nil
+	}
+	for i, basic := range colorBasicNames {
+		if name != basic {
+			continue
+		}
+		if bright {
+			i += 8
+		}
+		return ColorBasic(uint8(i)), nil
+	}
+
+	return Color{}, fmt.Errorf("unknown color or attribute %q", word)
+}
+
+// param returns SGR parameter of the color for the depth.
+func (c Color) param(depth ColorDepth, background bool) string {
+	base := 30
+	if background {
+		base = 40
+	}
+
+	switch c.kind {
+	case colorKindBasic:
+		return basicColorParam(c.index, base)
+	case colorKindIndexed:
+		if depth == ColorDepth16 {
+			if c.index < 16 {
+				return basicColorParam(c.index, base)
+			}
+			r, g, b := indexedColorRGB(c.index)
+			return basicColorParam(nearestBasicColor(r, g, b), base)
+		}
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(c.index))
+	case colorKindRGB:
+		switch depth {
+		case ColorDepthTrue:
+			return strconv.Itoa(base+8) + ";2;" +
+				strconv.Itoa(int(c.r)) + ";" + strconv.Itoa(int(c.g)) + ";" + strconv.Itoa(int(c.b))
+		case ColorDepth256:
+			return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(nearestIndexedColor(c.r, c.g, c.b)))
+		default:
+			return basicColorParam(nearestBasicColor(c.r, c.g, c.b), base)
+		}
+	default:
+		return ""
+	}
+}
+
+func basicColorParam(index uint8, base int) string {
+	if index < 8 {
+		return strconv.Itoa(base + int(index))
+	}
+	return strconv.Itoa(base + 60 + int(index) - 8)
+}
+
+// basicColorsRGB are colors of the xterm basic palette.
+var basicColorsRGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var colorCubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+func indexedColorRGB(index uint8) (r, g, b uint8) {
+	switch {
+	case index < 16:
+		c := basicColorsRGB[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		return colorCubeLevels[index/36], colorCubeLevels[index/6%6], colorCubeLevels[index%6]
+	default:
+		v := 8 + 10*(index-232)
+		return v, v, v
+	}
+}
+
+func nearestBasicColor(r, g, b uint8) uint8 {
+	var res uint8
+	best := -1
+	for i, c := range basicColorsRGB {
+		if d := colorDistance(r, g, b, c[0], c[1], c[2]); best < 0 || d < best {
+			res, best = uint8(i), d
+		}
+	}
+
+	return res
+}
+
+// nearestIndexedColor picks the nearest color of the 6×6×6 cube and the grayscale ramp of the 256 colors palette.
+func nearestIndexedColor(r, g, b uint8) uint8 {
+	var res uint8
+	best := -1
+	for i := 16; i < 256; i++ {
+		ir, ig, ib := indexedColorRGB(uint8(i))
+		if d := colorDistance(r, g, b, ir, ig, ib); best < 0 || d < best {
+			res, best = uint8(i), d
+		}
+	}
+
+	return res
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,120 @@
+package blog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		style string
+		depth ColorDepth
+		want  string
+	}{
+		{"empty", "", ColorDepthTrue, ""},
+		{"attributes", "bold underline", ColorDepth16, "\033[1;4m"},
+		{"basic", "red on bright-white", ColorDepth16, "\033[31;107m"},
+		{"indexed", "faint 244", ColorDepth256, "\033[2;38;5;244m"},
+		{"indexed-to-basic", "196", ColorDepth16, "\033[91m"},
+		{"true", "#ff8000", ColorDepthTrue, "\033[38;2;255;128;0m"},
+		{"true-to-indexed", "#ff0000", ColorDepth256, "\033[38;5;196m"},
+		{"true-to-basic", "on #000080", ColorDepth16, "\033[44m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style, err := ParseStyle(tt.style)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, style.escape(tt.depth))
+		})
+	}
+
+	_, err := ParseStyle("bold purple")
+	assert.Error(t, err)
+}
+
+func TestParseTheme(t *testing.T) {
+	theme, err := ParseTheme(`
+# Errors must be seen.
+base: light
+error: bold white on red; key: 33
+source-2: blue; source-8: red
+`)
+	assert.NoError(t, err)
+
+	want := ThemeLight()
+	want.Error = Style{Fg: ColorBasic(7), Bg: ColorBasic(1), Bold: true}
+	want.Key = Style{Fg: ColorIndexed(33)}
+	want.Sources[1] = Style{Fg: ColorBasic(4)}
+	want.Sources = append(want.Sources, Style{}, Style{Fg: ColorBasic(1)})
+	assert.Equal(t, want, theme)
+
+	_, err = ParseTheme("warning: red")
+	assert.Error(t, err)
+	_, err = ParseTheme("base: solarized")
+	assert.Error(t, err)
+	_, err = ParseTheme("source-0: red")
+	assert.Error(t, err)
+}
+
+func TestThemeFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme")
+	assert.NoError(t, os.WriteFile(path, []byte("info: green"), 0o644))
+
+	t.Setenv(ThemeEnv, "")
+	t.Setenv(ThemeFileEnv, path)
+	theme, err := ThemeFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Style{Fg: ColorBasic(2)}, theme.Info)
+
+	t.Setenv(ThemeEnv, "info: blue")
+	theme, err = ThemeFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Style{Fg: ColorBasic(4)}, theme.Info)
+}
+
+func TestPrettyWriterAutoColors(t *testing.T) {
+	write := func() string {
+		var buf bytes.Buffer
+		logger, err := NewLogger(NewPrettyWriter(&buf).WithAutoColors())
+		assert.NoError(t, err)
+		logger.Info(context.Background(), "message")
+		return buf.String()
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORFGBG", "0;15")
+	t.Setenv(ThemeEnv, "")
+	t.Setenv(ThemeFileEnv, "")
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	assert.NotContains(t, write(), "\033[", "buffer is not a terminal")
+
+	t.Setenv("CLICOLOR_FORCE", "1")
+	assert.Contains(t, write(), "\033[")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.NotContains(t, write(), "\033[")
+}
+
+func TestParseTerminalColor(t *testing.T) {
+	r, g, b, ok := parseTerminalColor("\033]11;rgb:ffff/8080/0000\033\\")
+	assert.True(t, ok)
+	assert.Equal(t, [3]uint16{0xffff, 0x8080, 0}, [3]uint16{r, g, b})
+
+	r, g, b, ok = parseTerminalColor("\033]11;rgb:f/80/000\a")
+	assert.True(t, ok)
+	assert.Equal(t, [3]uint16{0xffff, 0x8080, 0}, [3]uint16{r, g, b})
+
+	_, _, _, ok = parseTerminalColor("")
+	assert.False(t, ok)
+	_, _, _, ok = parseTerminalColor("\033]11;rgb:ffff/ffff\a")
+	assert.False(t, ok)
+}
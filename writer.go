@@ -31,3 +31,15 @@ func (s *syncWriter) Write(p []byte) (n int, err error) {
 
 	return n, nil
 }
+
+// Sync syncs the underlying writer if it has Sync() error method, like [os.File] does.
+func (s *syncWriter) Sync() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if ws, ok := s.w.(interface{ Sync() error }); ok {
+		return ws.Sync()
+	}
+
+	return nil
+}